@@ -0,0 +1,24 @@
+package hdsk
+
+import "hash"
+
+// ChildAAD derives a new child key from a given hash, master key, index, and associated data. The
+// associated data is folded into the fingerprint, leaving the child key and chain code identical
+// to those from Child, and the lineage of the child key only verifies with the same associated
+// data using LineageAAD. A nil associated data derives the same child key as Child.
+func ChildAAD(h func() hash.Hash, master *HDKey, index uint32, aad []byte) (HDKey, error) {
+	return Params{}.child(h, master, index, nil, aad)
+}
+
+// NodeAAD derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, derivation path, and associated data folded into the fingerprint at every
+// level. A nil associated data derives the same key as Node.
+func NodeAAD(h func() hash.Hash, master *HDKey, path HDPath, aad []byte) (HDKey, error) {
+	return Params{}.node(h, master, path, nil, aad)
+}
+
+// LineageAAD checks if a key is the direct child of a master key, from a given hash, child key,
+// master key, and the associated data the child key was derived with.
+func LineageAAD(h func() hash.Hash, child, master *HDKey, aad []byte) (bool, error) {
+	return lineage(h, child, master, aad)
+}
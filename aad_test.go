@@ -0,0 +1,67 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// aadVectors are fingerprints of the node at m/42/0/1/0 derived with associated data.
+var aadVectors = []struct {
+	aad         string
+	fingerprint string
+}{
+	{aad: "", fingerprint: "cfa16c41882773d9b42b56fbaae3850f"},
+	{aad: "tenant-a", fingerprint: "cdf47b9ed74841b59608a623a1e7d79c"},
+	{aad: "tenant-b", fingerprint: "bdd33aa9c6239c438763828d32de0c64"},
+}
+
+// TestAAD is a test for derivation with associated data folded into fingerprints.
+func TestAAD(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1, 0}
+	plain, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range aadVectors {
+		node, err := hdsk.NodeAAD(h, &master, path, []byte(v.aad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fp := hex.EncodeToString(node.Fingerprint); fp != v.fingerprint {
+			t.Fatalf(`mismatch for %q: expected %q, got %q`, v.aad, v.fingerprint, fp)
+		}
+		if !bytes.Equal(node.Key, plain.Key) {
+			t.Fatalf(`associated data must not change the node key`)
+		}
+	}
+	nilAAD, err := hdsk.ChildAAD(h, &master, 7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := hdsk.Child(h, &master, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(nilAAD.Fingerprint, child.Fingerprint) {
+		t.Fatalf(`nil associated data must derive the same fingerprint as Child`)
+	}
+	bound, err := hdsk.ChildAAD(h, &master, 7, []byte("tenant-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for aad, expected := range map[string]bool{"tenant-a": true, "tenant-b": false, "": false} {
+		lineage, err := hdsk.LineageAAD(h, &bound, &master, []byte(aad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lineage != expected {
+			t.Fatalf(`lineage with %q: expected %v, got %v`, aad, expected, lineage)
+		}
+	}
+}
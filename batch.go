@@ -0,0 +1,166 @@
+package hdsk
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"runtime"
+	"sync"
+)
+
+// DeriveRange derives the child keys of a parent key for each index from start up to but not
+// including end, from a given hash, parent key, start index, and end index.
+func DeriveRange(h func() hash.Hash, parent *HDKey, start, end uint32) ([]HDKey, error) {
+	return DeriveRangeContext(context.Background(), h, parent, start, end)
+}
+
+// DeriveRangeContext derives the child keys of a parent key for each index from start up to but
+// not including end, like DeriveRange, from a given context, hash, parent key, start index, and
+// end index. The context is checked before each derivation, and when it is done, the keys derived
+// so far are returned with an error wrapping the context error.
+func DeriveRangeContext(ctx context.Context, h func() hash.Hash, parent *HDKey, start, end uint32) ([]HDKey, error) {
+	if start > end {
+		return nil, fmt.Errorf(`range start %d exceeds end %d`, start, end)
+	}
+	d, err := NewChildDeriver(h, parent) // Validate the parent key once for the range
+	if err != nil {
+		return nil, fmt.Errorf(`range, %w`, err)
+	}
+	keys := make([]HDKey, 0, end-start) // Allocate slice for the child keys
+	for index := start; index < end; index++ {
+		if err := ctx.Err(); err != nil {
+			return keys, fmt.Errorf(`range index %d, %w`, index, err) // Return the keys derived before the context was done
+		}
+		key, err := d.Child(index) // Derive the child key for the current index
+		if err != nil {
+			return nil, fmt.Errorf(`range index %d, %w`, index, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil // Return the child keys
+}
+
+// DeriveChildren derives the child keys of a parent key for each of the given indices, from a
+// given hash, parent key, and indices, returning each index mapped to its child key. Repeated
+// indices are derived once, and on the first error, the keys derived so far are wiped.
+func DeriveChildren(h func() hash.Hash, parent *HDKey, indices []uint32) (map[uint32]HDKey, error) {
+	d, err := NewChildDeriver(h, parent) // Validate the parent key once for the indices
+	if err != nil {
+		return nil, fmt.Errorf(`children, %w`, err)
+	}
+	keys := make(map[uint32]HDKey, len(indices)) // Allocate map for the child keys
+	for _, index := range indices {
+		if _, ok := keys[index]; ok {
+			continue // Skip indices already derived
+		}
+		key, err := d.Child(index) // Derive the child key for the current index
+		if err != nil {
+			for _, derived := range keys {
+				derived.Zero() // Wipe the keys derived before the error
+			}
+			return nil, fmt.Errorf(`children index %d, %w`, index, err)
+		}
+		keys[index] = key
+	}
+	return keys, nil // Return the child keys by index
+}
+
+// ChildDeriver derives child keys of a single parent key. The parent key is validated and the
+// parameters are prepared once, rather than for every child. The salt of each child is keyed by
+// its index, so salts remain derived for every child. The parent key must not be modified while
+// the deriver is in use.
+type ChildDeriver struct {
+	parent parentKey // Parent key and prepared parameters.
+}
+
+// NewChildDeriver creates a new child deriver from a given hash and parent key.
+func NewChildDeriver(h func() hash.Hash, parent *HDKey) (*ChildDeriver, error) {
+	return Params{}.ChildDeriver(h, parent)
+}
+
+// ChildDeriver creates a new child deriver from a given hash and parent key, using the parameters.
+func (p Params) ChildDeriver(h func() hash.Hash, parent *HDKey) (*ChildDeriver, error) {
+	b, err := p.prepare(h, parent) // Validate the parent key and prepare the parameters
+	if err != nil {
+		return nil, err
+	}
+	return &ChildDeriver{parent: b}, nil
+}
+
+// Child derives a new child key of the parent key from a given index, identical to the child key
+// derived by Child with the same parameters.
+func (d *ChildDeriver) Child(index uint32) (HDKey, error) {
+	info1, hardened, info2 := d.parent.p.childInfo(index, nil) // Construct the info for the index
+	key, err := d.parent.derive(hardened, info1, info2, nil)
+	if err != nil {
+		return HDKey{}, err
+	}
+	d.parent.p.retain(&key, d.parent.master, nil, nil)
+	return key, nil // Return the child HD key
+}
+
+// NodeBatch derives keys at nodes in a hierarchy descending from a master key, from a given hash,
+// master key, and derivation paths. Nodes are derived concurrently by up to GOMAXPROCS workers,
+// each creating its own hash instances, and keys are returned in the order of the paths.
+func NodeBatch(h func() hash.Hash, master *HDKey, paths []HDPath) ([]HDKey, error) {
+	return NodeBatchContext(context.Background(), h, master, paths)
+}
+
+// NodeBatchContext derives keys at nodes in a hierarchy descending from a master key, like
+// NodeBatch, from a given context, hash, master key, and derivation paths. Workers check the
+// context before each derivation and stop taking paths when it is done, in which case the keys
+// derived so far are wiped, and an error wrapping the context error is returned.
+func NodeBatchContext(ctx context.Context, h func() hash.Hash, master *HDKey, paths []HDPath) ([]HDKey, error) {
+	keys := make([]HDKey, len(paths))                 // Allocate slice for the node keys
+	errs := make([]error, len(paths))                 // Allocate slice for the derivation errors
+	workers := min(runtime.GOMAXPROCS(0), len(paths)) // Bound the number of workers
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if errs[i] = ctx.Err(); errs[i] != nil {
+					continue // Skip the remaining paths once the context is done
+				}
+				keys[i], errs[i] = Node(h, master, paths[i]) // Derive the node key for the current path
+			}
+		}()
+	}
+	sent := dispatch(ctx, jobs, len(paths)) // Send the index of each path to the workers
+	wg.Wait()
+	if sent < len(paths) {
+		wipe(keys)
+		return nil, fmt.Errorf(`batch path %d, %w`, sent, ctx.Err())
+	}
+	for i, err := range errs {
+		if err != nil {
+			wipe(keys)
+			return nil, fmt.Errorf(`batch path %d, %w`, i, err)
+		}
+	}
+	return keys, nil // Return the node keys
+}
+
+// dispatch sends the indices from zero up to but not including a given count to the workers from
+// a given context and channel of jobs, stopping when the context is done, and closes the channel.
+// The number of indices sent is returned.
+func dispatch(ctx context.Context, jobs chan<- int, count int) int {
+	defer close(jobs)
+	for i := range count {
+		select {
+		case <-ctx.Done():
+			return i
+		case jobs <- i:
+		}
+	}
+	return count
+}
+
+// wipe wipes the given keys with Zero.
+func wipe(keys []HDKey) {
+	for i := range keys {
+		keys[i].Zero()
+	}
+}
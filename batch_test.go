@@ -0,0 +1,243 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"sync"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestDeriveRange is a test for deriving a range of child keys.
+func TestDeriveRange(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	keys, err := hdsk.DeriveRange(h, &master, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 5 {
+		t.Fatalf(`expected 5 keys, got %d`, len(keys))
+	}
+	for i, key := range keys {
+		child, err := hdsk.Child(h, &master, uint32(5+i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(key.Key, child.Key) {
+			t.Fatalf(`mismatch for index %d`, 5+i)
+		}
+	}
+	empty, err := hdsk.DeriveRange(h, &master, 3, 3)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf(`expected empty range, got %d keys, %v`, len(empty), err)
+	}
+	if _, err := hdsk.DeriveRange(h, &master, 4, 3); err == nil {
+		t.Fatalf(`expected error for start exceeding end`)
+	}
+}
+
+// TestNodeBatch is a test for deriving nodes concurrently.
+func TestNodeBatch(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	paths := make([]hdsk.HDPath, 64)
+	for i := range paths {
+		paths[i] = hdsk.HDPath{42, uint32(i % 4), uint32(i), uint32(i) | hdsk.Hardened}
+	}
+	keys, err := hdsk.NodeBatch(h, &master, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, path := range paths {
+		node, err := hdsk.Node(h, &master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(keys[i].Key, node.Key) || !bytes.Equal(keys[i].Fingerprint, node.Fingerprint) {
+			t.Fatalf(`mismatch for path %d`, i)
+		}
+	}
+	empty, err := hdsk.NodeBatch(h, &master, nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf(`expected no keys, got %d keys, %v`, len(empty), err)
+	}
+}
+
+// TestBatchContext is a test for cancelling batch derivation.
+func TestBatchContext(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	counting := func() hash.Hash {
+		if calls++; calls == 40 {
+			cancel() // Cancel in the middle of the range
+		}
+		return h()
+	}
+	keys, err := hdsk.DeriveRangeContext(ctx, counting, &master, 0, 1000)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf(`expected %v, got %v`, context.Canceled, err)
+	}
+	if len(keys) == 0 || len(keys) >= 1000 {
+		t.Fatalf(`expected a partial range, got %d keys`, len(keys))
+	}
+	for i, key := range keys {
+		child, err := hdsk.Child(h, &master, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !key.Equal(&child) {
+			t.Fatalf(`mismatch for index %d`, i)
+		}
+	}
+	paths := make([]hdsk.HDPath, 1000)
+	for i := range paths {
+		paths[i] = hdsk.HDPath{42, uint32(i)}
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	calls = 0
+	var mu sync.Mutex
+	counting = func() hash.Hash {
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls == 100 {
+			cancel() // Cancel in the middle of the batch
+		}
+		return h()
+	}
+	batch, err := hdsk.NodeBatchContext(ctx, counting, &master, append(paths, nil))
+	if !errors.Is(err, context.Canceled) || batch != nil {
+		t.Fatalf(`expected %v and no keys, got %d keys, %v`, context.Canceled, len(batch), err)
+	}
+	if !bytes.Equal(master.Key, testMaster(t, h).Key) {
+		t.Fatalf(`cancelling must not wipe the master key`)
+	}
+	if _, err := hdsk.NodeBatchContext(context.Background(), h, &master, paths[:8]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestChildDeriver is a test for deriving child keys of a single parent key.
+func TestChildDeriver(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	for _, p := range []hdsk.Params{{}, {Domain: "app", SplitExpand: true, KeyLen: 48}} {
+		parent := master
+		if p.KeyLen != 0 {
+			var err error
+			if parent, err = (hdsk.Params{AllowWeak: true, KeyLen: p.KeyLen}).Master(h, make([]byte, 32)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		d, err := p.ChildDeriver(h, &parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range []uint32{0, 1, 42, 7 | hdsk.Hardened} {
+			key, err := d.Child(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			child, err := p.Child(h, &parent, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !key.Equal(&child) {
+				t.Fatalf(`mismatch for index %d`, index)
+			}
+		}
+	}
+	malformed := hdsk.HDKey{Key: master.Key, Code: master.Code[:16]}
+	if _, err := hdsk.NewChildDeriver(h, &malformed); err == nil {
+		t.Fatalf(`expected error for malformed parent key`)
+	}
+}
+
+// TestDeriveChildren is a test for deriving child keys mapped by index.
+func TestDeriveChildren(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	indices := []uint32{42, 0, 7 | hdsk.Hardened, 1, 42}
+	keys, err := hdsk.DeriveChildren(h, &master, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Fatalf(`expected 4 child keys, got %d`, len(keys))
+	}
+	for _, index := range indices {
+		child, err := hdsk.Child(h, &master, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, ok := keys[index]
+		if !ok || !key.Equal(&child) {
+			t.Fatalf(`mismatch for index %d`, index)
+		}
+	}
+	again, err := hdsk.DeriveChildren(h, &master, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, key := range keys {
+		if other := again[index]; !key.Equal(&other) {
+			t.Fatalf(`expected deterministic child key for index %d`, index)
+		}
+	}
+	empty, err := hdsk.DeriveChildren(h, &master, nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf(`expected no child keys, got %v, %v`, empty, err)
+	}
+	malformed := hdsk.HDKey{Key: master.Key, Code: master.Code[:16]}
+	if _, err := hdsk.DeriveChildren(h, &malformed, indices); err == nil {
+		t.Fatalf(`expected error for malformed parent key`)
+	}
+}
+
+// BenchmarkDeriveRange is a benchmark for deriving a range of child keys.
+func BenchmarkDeriveRange(b *testing.B) {
+	h := sha256.New
+	master := testMaster(b, h)
+	for b.Loop() {
+		if _, err := hdsk.DeriveRange(h, &master, 0, 100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkChildLoop is a benchmark for deriving child keys in a loop.
+func BenchmarkChildLoop(b *testing.B) {
+	h := sha256.New
+	master := testMaster(b, h)
+	for b.Loop() {
+		for index := range uint32(100) {
+			if _, err := hdsk.Child(h, &master, index); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkChildDeriver is a benchmark for deriving child keys in a loop with a child deriver,
+// for comparison with BenchmarkChildLoop.
+func BenchmarkChildDeriver(b *testing.B) {
+	h := sha256.New
+	master := testMaster(b, h)
+	d, err := hdsk.NewChildDeriver(h, &master)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		for index := range uint32(100) {
+			if _, err := d.Child(index); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package hdsk
+
+import "hash"
+
+// ChildWithContext derives a new child key from a given hash, master key, index, and context. The
+// context is mixed into the HKDF info following the index, so that the same index under the same
+// master key derives distinct child keys for distinct contexts, such as tenant identifiers. A nil
+// or empty context derives the same child key as Child.
+func ChildWithContext(h func() hash.Hash, master *HDKey, index uint32, ctx []byte) (HDKey, error) {
+	return Params{}.child(h, master, index, ctx, nil)
+}
+
+// NodeWithContext derives a new key at a node in a hierarchy descending from a master key, from a
+// given hash, master key, derivation path, and context mixed into the HKDF info at every level.
+// A nil or empty context derives the same key as Node.
+func NodeWithContext(h func() hash.Hash, master *HDKey, path HDPath, ctx []byte) (HDKey, error) {
+	return Params{}.node(h, master, path, ctx, nil)
+}
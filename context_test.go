@@ -0,0 +1,73 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestChildWithContext is a test for deriving child keys bound to a context.
+func TestChildWithContext(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	for _, index := range []uint32{0, 7 | hdsk.Hardened} {
+		child, err := hdsk.Child(h, &master, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ctx := range [][]byte{nil, {}} {
+			bound, err := hdsk.ChildWithContext(h, &master, index, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bound.Equal(&child) {
+				t.Fatalf(`index %d: empty context must derive the same child key`, index)
+			}
+		}
+		a, err := hdsk.ChildWithContext(h, &master, index, []byte("tenant-a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := hdsk.ChildWithContext(h, &master, index, []byte("tenant-b"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Equal(&child) || a.Equal(&b) {
+			t.Fatalf(`index %d: distinct contexts must derive distinct child keys`, index)
+		}
+	}
+}
+
+// TestNodeWithContext is a test for deriving nodes bound to a context.
+func TestNodeWithContext(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1, 0}
+	node, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unbound, err := hdsk.NodeWithContext(h, &master, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unbound.Equal(&node) {
+		t.Fatalf(`nil context must derive the same node`)
+	}
+	ctx := []byte("tenant-a")
+	bound, err := hdsk.NodeWithContext(h, &master, path, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := master
+	for _, index := range path {
+		key, err = hdsk.ChildWithContext(h, &key, index, ctx) // Derive each level with the context
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bound.Equal(&node) || !bound.Equal(&key) {
+		t.Fatalf(`context must be mixed into every level of the node`)
+	}
+}
@@ -0,0 +1,46 @@
+package hdsk
+
+import "hash"
+
+// Deriver derives keys and parses derivation paths with a single hash function, so that a master
+// key and its descendants are always derived with the same hash.
+type Deriver struct {
+	Params Params           // Parameters for key derivation.
+	h      func() hash.Hash // Hash for key derivation and derivation paths.
+}
+
+// NewDeriver creates a new deriver from a given hash.
+func NewDeriver(h func() hash.Hash) *Deriver {
+	return &Deriver{h: h}
+}
+
+// Path parses a new derivation path from a given string and schema, using the parameters.
+func (d *Deriver) Path(str string, schema HDSchema) (HDPath, error) {
+	return d.Params.Path(d.h, str, schema)
+}
+
+// Master derives a new master key from a given secret.
+func (d *Deriver) Master(secret []byte) (HDKey, error) {
+	return d.Params.Master(d.h, secret)
+}
+
+// Child derives a new child key from a given master key and index.
+func (d *Deriver) Child(master *HDKey, index uint32) (HDKey, error) {
+	return d.Params.Child(d.h, master, index)
+}
+
+// Node derives a new key at a node in a hierarchy descending from a master key, from a given
+// master key and derivation path.
+func (d *Deriver) Node(master *HDKey, path HDPath) (HDKey, error) {
+	return d.Params.Node(d.h, master, path)
+}
+
+// Lineage checks if a key is the direct child of a master key, from a given child key and master key.
+func (d *Deriver) Lineage(child, master *HDKey) (bool, error) {
+	return Lineage(d.h, child, master)
+}
+
+// ChildDeriver creates a new child deriver from a given parent key.
+func (d *Deriver) ChildDeriver(parent *HDKey) (*ChildDeriver, error) {
+	return d.Params.ChildDeriver(d.h, parent)
+}
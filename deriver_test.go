@@ -0,0 +1,48 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestDeriver is a test for deriving keys with a deriver.
+func TestDeriver(t *testing.T) {
+	d := hdsk.NewDeriver(sha256.New)
+	d.Params.AllowWeak = true // Allow the weak secret of the test vectors
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := d.Master(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		path, err := d.Path(v.path, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dk, err := d.Node(&master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dkHex := hex.EncodeToString(dk.Key)
+		if dkHex != v.key {
+			t.Fatalf(`mismatch for %s: expected %q, got %q`, v.path, v.key, dkHex)
+		}
+		child, err := d.Child(&dk, 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lineage, err := d.Lineage(&child, &dk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !lineage {
+			t.Fatalf(`invalid key lineage encountered for child of %q`, dkHex)
+		}
+	}
+}
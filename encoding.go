@@ -0,0 +1,229 @@
+package hdsk
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jacobhaap/go-hdsk/internal/base58"
+)
+
+// binaryVersion is the version of the binary encoding of HD keys.
+const binaryVersion byte = 1
+
+// MarshalBinary encodes the HD key in binary form, as a version byte, the big endian depth, and
+// the key, chain code, and fingerprint each prefixed by a length byte.
+func (k HDKey) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 5+3+len(k.Key)+len(k.Code)+len(k.Fingerprint))
+	b = append(b, binaryVersion)
+	b = binary.BigEndian.AppendUint32(b, k.Depth) // Append the bytes of encoded depth
+	for _, field := range []struct {
+		name  string
+		value []byte
+	}{{"key", k.Key}, {"chain code", k.Code}, {"fingerprint", k.Fingerprint}} {
+		if len(field.value) > 0xFF {
+			return nil, fmt.Errorf(`%s cannot exceed 255 bytes, got %d`, field.name, len(field.value))
+		}
+		b = append(b, byte(len(field.value))) // Append the length of the field
+		b = append(b, field.value...)
+	}
+	return b, nil // Return the binary encoded HD key
+}
+
+// UnmarshalBinary decodes an HD key from binary form, with a depth of at most MaxDepth.
+func (k *HDKey) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return errors.New(`truncated binary HD key`)
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf(`unknown binary HD key version %d`, data[0])
+	}
+	depth := binary.BigEndian.Uint32(data[1:5]) // Decode the depth
+	if err := checkDepth(depth); err != nil {
+		return err
+	}
+	data = data[5:]
+	fields := make([][]byte, 3) // Decoded key, chain code, and fingerprint
+	for i := range fields {
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return errors.New(`truncated binary HD key`)
+		}
+		fields[i] = append([]byte(nil), data[1:1+int(data[0])]...) // Copy each field from its length prefix
+		data = data[1+int(data[0]):]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf(`trailing %d bytes after binary HD key`, len(data))
+	}
+	if len(fields[2]) != FingerprintLen {
+		return fmt.Errorf(`fingerprint must be %d bytes, got %d`, FingerprintLen, len(fields[2]))
+	}
+	*k = HDKey{
+		Key:         fields[0],
+		Code:        fields[1],
+		Depth:       depth,
+		Fingerprint: fields[2],
+	}
+	return nil
+}
+
+// checkDepth checks that a given depth of a decoded HD key does not exceed MaxDepth, so that keys
+// which cannot be extended with Child are not decoded.
+func checkDepth(depth uint32) error {
+	if depth > MaxDepth {
+		return fmt.Errorf(`decoded HD key at depth %d, %w`, depth, ErrMaxDepth)
+	}
+	return nil
+}
+
+// WriteTo writes the HD key to a writer in binary form, as encoded by MarshalBinary, so that keys
+// can be appended to a stream and read back with ReadHDKey.
+func (k *HDKey) WriteTo(w io.Writer) (int64, error) {
+	b, err := k.MarshalBinary() // Encode the HD key in binary form
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadHDKey reads the next HD key in binary form from a reader, returning io.EOF when the reader
+// ends before the next key, and an error wrapping io.ErrUnexpectedEOF when it ends within a key.
+func ReadHDKey(r io.Reader) (HDKey, error) {
+	b := make([]byte, 5, 5+3+KeyLen+CodeLen+FingerprintLen)
+	if _, err := io.ReadFull(r, b[:1]); err != nil {
+		return HDKey{}, err // Return io.EOF at the end of the stream
+	}
+	if _, err := io.ReadFull(r, b[1:5]); err != nil {
+		return HDKey{}, fmt.Errorf(`partial binary HD key, %w`, noEOF(err))
+	}
+	for range 3 {
+		var length [1]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return HDKey{}, fmt.Errorf(`partial binary HD key, %w`, noEOF(err))
+		}
+		field := make([]byte, length[0])
+		if _, err := io.ReadFull(r, field); err != nil {
+			return HDKey{}, fmt.Errorf(`partial binary HD key, %w`, noEOF(err))
+		}
+		b = append(append(b, length[0]), field...) // Append each length prefixed field
+	}
+	var key HDKey
+	if err := key.UnmarshalBinary(b); err != nil {
+		return HDKey{}, err
+	}
+	return key, nil // Return the HD key
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, for readers ending within a key.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// jsonKey is the JSON form of an HD key.
+type jsonKey struct {
+	Key         string `json:"key"`
+	Code        string `json:"code"`
+	Depth       uint32 `json:"depth"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// MarshalJSON encodes the HD key as JSON, with the key, chain code, and fingerprint as hex strings.
+func (k HDKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonKey{
+		Key:         hex.EncodeToString(k.Key),
+		Code:        hex.EncodeToString(k.Code),
+		Depth:       k.Depth,
+		Fingerprint: hex.EncodeToString(k.Fingerprint),
+	})
+}
+
+// UnmarshalJSON decodes an HD key from JSON. The key and chain code must be between 16 and 255
+// bytes and the fingerprint 16 bytes, or all three must be empty as in the zero HD key.
+func (k *HDKey) UnmarshalJSON(data []byte) error {
+	var jk jsonKey
+	if err := json.Unmarshal(data, &jk); err != nil {
+		return err
+	}
+	if empty := jk.Key == ""; empty != (jk.Code == "") || empty != (jk.Fingerprint == "") {
+		return errors.New(`key, chain code, and fingerprint must all be set, or all be empty as in the zero HD key`)
+	}
+	if err := checkDepth(jk.Depth); err != nil {
+		return err
+	}
+	fields := make([][]byte, 3) // Decoded key, chain code, and fingerprint
+	for i, field := range []struct {
+		name     string
+		value    string
+		min, max int
+	}{{"key", jk.Key, 16, 255}, {"chain code", jk.Code, 16, 255}, {"fingerprint", jk.Fingerprint, FingerprintLen, FingerprintLen}} {
+		if field.value == "" {
+			continue // Leave the fields of the zero HD key nil
+		}
+		b, err := hex.DecodeString(field.value)
+		if err != nil {
+			return fmt.Errorf(`invalid hex for %s, %w`, field.name, err)
+		}
+		if len(b) < field.min || len(b) > field.max {
+			return fmt.Errorf(`%s must be between %d and %d bytes, got %d`, field.name, field.min, field.max, len(b))
+		}
+		fields[i] = b
+	}
+	*k = HDKey{
+		Key:         fields[0],
+		Code:        fields[1],
+		Depth:       jk.Depth,
+		Fingerprint: fields[2],
+	}
+	return nil
+}
+
+// extendedLen is the length of the payload of an extended key string, from the version, depth,
+// fingerprint, chain code, and key.
+const extendedLen = 4 + 4 + FingerprintLen + CodeLen + KeyLen
+
+// Encode encodes the HD key as an extended key string from a given version, as a base58check
+// string of the version, the big endian depth, the fingerprint, the chain code, and the key.
+// Extended key strings hold 32 byte keys and chain codes and 16 byte fingerprints.
+func (k *HDKey) Encode(version [4]byte) (string, error) {
+	if len(k.Key) != KeyLen || len(k.Code) != CodeLen || len(k.Fingerprint) != FingerprintLen {
+		return "", fmt.Errorf(`extended key requires a %d byte key, %d byte chain code, and %d byte fingerprint, got %d, %d, and %d`, KeyLen, CodeLen, FingerprintLen, len(k.Key), len(k.Code), len(k.Fingerprint))
+	}
+	b := make([]byte, 0, extendedLen)
+	b = append(b, version[:]...)
+	b = binary.BigEndian.AppendUint32(b, k.Depth) // Append the bytes of encoded depth
+	b = append(b, k.Fingerprint...)
+	b = append(b, k.Code...)
+	b = append(b, k.Key...)
+	return base58.CheckEncode(b), nil // Return the extended key string
+}
+
+// Decode decodes an HD key and its version from an extended key string, verifying its checksum
+// and that its depth does not exceed MaxDepth.
+func Decode(s string) (HDKey, [4]byte, error) {
+	var version [4]byte
+	b, err := base58.CheckDecode(s) // Decode the payload and verify its checksum
+	if err != nil {
+		return HDKey{}, version, fmt.Errorf(`extended key, %w`, err)
+	}
+	if len(b) != extendedLen {
+		return HDKey{}, version, fmt.Errorf(`extended key payload must be %d bytes, got %d`, extendedLen, len(b))
+	}
+	copy(version[:], b[:4])
+	if err := checkDepth(binary.BigEndian.Uint32(b[4:8])); err != nil {
+		return HDKey{}, version, fmt.Errorf(`extended key, %w`, err)
+	}
+	fp, code := 8+FingerprintLen, 8+FingerprintLen+CodeLen // Offsets past the fingerprint and chain code
+	key := HDKey{
+		Depth:       binary.BigEndian.Uint32(b[4:8]),
+		Fingerprint: b[8:fp:fp],
+		Code:        b[fp:code:code],
+		Key:         b[code:],
+	}
+	return key, version, nil // Return the decoded HD key and version
+}
@@ -0,0 +1,207 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestBinary is a test for the binary encoding of HD keys.
+func TestBinary(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := node.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded hdsk.HDKey
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Key, node.Key) || !bytes.Equal(decoded.Code, node.Code) ||
+		decoded.Depth != node.Depth || !bytes.Equal(decoded.Fingerprint, node.Fingerprint) {
+		t.Fatalf(`decoded HD key does not match encoded HD key`)
+	}
+	for i := range len(b) {
+		if err := decoded.UnmarshalBinary(b[:i]); err == nil {
+			t.Fatalf(`expected error for binary HD key truncated to %d bytes`, i)
+		}
+	}
+	unknown := bytes.Clone(b)
+	unknown[0] = 0xFF // Set an unknown version
+	if err := decoded.UnmarshalBinary(unknown); err == nil {
+		t.Fatalf(`expected error for unknown version`)
+	}
+	short := node
+	short.Fingerprint = node.Fingerprint[:8] // Truncate the fingerprint
+	b, err = short.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(b); err == nil {
+		t.Fatalf(`expected error for 8 byte fingerprint`)
+	}
+	deep := node
+	deep.Depth = hdsk.MaxDepth + 1
+	b, err = deep.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(b); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+}
+
+// TestJSON is a test for the JSON encoding of HD keys.
+func TestJSON(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"key":"7bc626147a8441fd808a42dbfb889a083f1cbd3065b5921e1a28a53db0d3781f"`) {
+		t.Fatalf(`expected hex encoded key in %s`, b)
+	}
+	var decoded hdsk.HDKey
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Key, node.Key) || !bytes.Equal(decoded.Code, node.Code) ||
+		decoded.Depth != node.Depth || !bytes.Equal(decoded.Fingerprint, node.Fingerprint) {
+		t.Fatalf(`decoded HD key does not match encoded HD key`)
+	}
+	b, err = json.Marshal(hdsk.HDKey{}) // Encode the zero HD key
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zero hdsk.HDKey
+	if err := json.Unmarshal(b, &zero); err != nil {
+		t.Fatal(err)
+	}
+	if zero.Key != nil || zero.Code != nil || zero.Depth != 0 || zero.Fingerprint != nil {
+		t.Fatalf(`zero HD key does not round trip, got %+v`, zero)
+	}
+	for _, str := range []string{
+		`{"key":"zz","code":"","depth":0,"fingerprint":""}`,
+		`{"key":"00","code":"","depth":0,"fingerprint":""}`,
+		`{"key":"","code":"","depth":0,"fingerprint":"0011"}`,
+		`{"key":"` + hex.EncodeToString(node.Key) + `","code":"","depth":0,"fingerprint":""}`,
+		`{"key":"` + hex.EncodeToString(node.Key) + `","code":"` + hex.EncodeToString(node.Code) + `","depth":0,"fingerprint":""}`,
+		`{"key":"","code":"` + hex.EncodeToString(node.Code) + `","depth":0,"fingerprint":"` + hex.EncodeToString(node.Fingerprint) + `"}`,
+		`{"key":"","code":"","depth":256,"fingerprint":""}`,
+	} {
+		if err := json.Unmarshal([]byte(str), &decoded); err == nil {
+			t.Fatalf(`expected error for %s`, str)
+		}
+	}
+}
+
+// TestEncode is a test for encoding HD keys as extended key strings.
+func TestEncode(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := [4]byte{'h', 'd', 's', 'k'}
+	s, err := node.Encode(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, v, err := hdsk.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != version || !decoded.Equal(&node) {
+		t.Fatalf(`extended key did not round trip`)
+	}
+	tampered := []byte(s)
+	if i := len(tampered) / 2; tampered[i] == 'A' {
+		tampered[i] = 'B' // Change a single character within the alphabet
+	} else {
+		tampered[i] = 'A'
+	}
+	if _, _, err := hdsk.Decode(string(tampered)); err == nil {
+		t.Fatalf(`expected error for bad checksum`)
+	}
+	deep := node
+	deep.Depth = hdsk.MaxDepth + 1
+	s, err = deep.Encode(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := hdsk.Decode(s); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+	short := node
+	short.Code = short.Code[:16]
+	if _, err := short.Encode(version); err == nil {
+		t.Fatalf(`expected error for short chain code`)
+	}
+}
+
+// TestReadHDKey is a test for writing HD keys to a stream and reading them back.
+func TestReadHDKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	keys, err := hdsk.DeriveRange(h, &master, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for i := range keys {
+		n, err := keys[i].WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := keys[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(b)) {
+			t.Fatalf(`expected %d bytes written, got %d`, len(b), n)
+		}
+	}
+	stream := buf.Bytes()
+	r := bytes.NewReader(stream)
+	for i := range keys {
+		key, err := hdsk.ReadHDKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !key.Equal(&keys[i]) {
+			t.Fatalf(`key %d did not round trip`, i)
+		}
+	}
+	if _, err := hdsk.ReadHDKey(r); err != io.EOF {
+		t.Fatalf(`expected %v at end of stream, got %v`, io.EOF, err)
+	}
+	for _, n := range []int{1, 40, 87} {
+		r := bytes.NewReader(stream[:len(stream)-n]) // Truncate the stream within the last key
+		for range len(keys) - 1 {
+			if _, err := hdsk.ReadHDKey(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := hdsk.ReadHDKey(r); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf(`truncated by %d bytes: expected %v, got %v`, n, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package hdsk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strconv"
+)
+
+// Epoch derives the root of a subtree at a given epoch from a given hash and epoch, rotating the
+// key, chain code, and every descendant of the key while keeping the structure of derivation
+// paths below it. Epoch 0 returns a copy of the key, and each later epoch derives an independent
+// root from the key and chain code, mixing the epoch into the salt and HKDF info. Epoch roots keep
+// the depth of the key, and their fingerprints link them to the key, so Lineage verifies an epoch
+// root against the key. Keys must have the default lengths of keys and chain codes, and be below
+// MaxDepth.
+func (k *HDKey) Epoch(h func() hash.Hash, epoch uint32) (HDKey, error) {
+	if epoch == 0 {
+		return k.Clone(), nil // Return a copy of the key for epoch 0
+	}
+	info1 := binary.BigEndian.AppendUint32([]byte("EPOCH"), epoch) // Context info from EPOCH + bytes of encoded epoch
+	info2 := "EPOCH" + strconv.FormatUint(uint64(epoch), 10)       // Construct info for HKDF from EPOCH + epoch string
+	key, err := Params{}.derive(h, k, true, info1, info2, nil)     // Derive the root from the key and chain code
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`epoch %d, %w`, epoch, err)
+	}
+	key.Depth = k.Depth // Keep the depth of the key
+	return key, nil     // Return the epoch root HD key
+}
@@ -0,0 +1,74 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// epochVectors are test vectors for the epoch roots of the node at the first vector path.
+var epochVectors = []struct {
+	epoch uint32
+	key   string
+}{
+	{0, "7bc626147a8441fd808a42dbfb889a083f1cbd3065b5921e1a28a53db0d3781f"},
+	{1, "ff6cdea91a6f17a65f920440d30f3cccefba1acd8de91c9fd54804f1afbeabf3"},
+	{2, "eb7934d97352ef8db102ee9ff87e3fa8f4a863c05a5648c86cb94d4b88d76e45"},
+}
+
+// TestEpoch is a test for rotating subtrees to new epochs.
+func TestEpoch(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]hdsk.HDKey, 0, len(epochVectors))
+	for _, v := range epochVectors {
+		root, err := node.Epoch(h, v.epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key := hex.EncodeToString(root.Key); key != v.key {
+			t.Fatalf(`mismatch for epoch %d: expected %q, got %q`, v.epoch, v.key, key)
+		}
+		if root.Depth != node.Depth {
+			t.Fatalf(`epoch %d: expected depth %d, got %d`, v.epoch, node.Depth, root.Depth)
+		}
+		again, err := node.Epoch(h, v.epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !root.Equal(&again) {
+			t.Fatalf(`epoch %d must be reproducible`, v.epoch)
+		}
+		roots = append(roots, root)
+	}
+	if !roots[0].Equal(&node) {
+		t.Fatalf(`epoch 0 must equal the original node`)
+	}
+	lineage, err := hdsk.Lineage(h, &roots[1], &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lineage {
+		t.Fatalf(`expected the epoch root to verify against the node`)
+	}
+	children := make([][]byte, 0, len(roots))
+	for _, root := range roots {
+		child, err := hdsk.Child(h, &root, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, other := range children {
+			if bytes.Equal(other, child.Key) {
+				t.Fatalf(`expected independent subtrees for distinct epochs`)
+			}
+		}
+		children = append(children, child.Key)
+	}
+}
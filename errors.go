@@ -0,0 +1,23 @@
+package hdsk
+
+import (
+	"errors"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// Errors returned by the package, wrapped with context so they can be matched with errors.Is.
+var (
+	ErrKnownWeakSecret       = errors.New(`known weak secret`)        // Master key from a known weak secret.
+	ErrSchemaTooManySegments = errors.New(`too many schema segments`) // Schema exceeding 256 segments.
+	ErrBadRoot               = errors.New(`bad root`)                 // Schema or derivation path not beginning with "m".
+	ErrInvalidSegment        = errors.New(`invalid schema segment`)   // Schema segment without a label or type, or out of order.
+	ErrInvalidType           = errors.New(`invalid schema type`)      // Schema segment of an unknown or malformed type.
+	ErrTooManyIndices        = errors.New(`too many indices`)         // Derivation path with more indices than schema segments.
+	ErrMissingIndex          = errors.New(`missing index`)            // Derivation path without a required index.
+	ErrEmptyIndex            = errors.New(`empty index`)              // Derivation path with an empty index between or after slashes.
+	ErrIndexOutOfRange       = utils.ErrIndexOutOfRange               // Index outside of the non-hardened range or its bounds.
+	ErrMaxDepth              = errors.New(`maximum depth exceeded`)   // Child key beyond MaxDepth.
+	ErrInvalidMnemonic       = errors.New(`invalid mnemonic`)         // Mnemonic of the wrong length, unknown words, or a bad checksum.
+	ErrOutputTooLong         = errors.New(`output too long`)          // Expand longer than 255 blocks of the hash.
+)
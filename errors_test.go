@@ -0,0 +1,62 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestErrors is a test for matching errors of schemas and derivation paths.
+func TestErrors(t *testing.T) {
+	h := sha256.New
+	for _, c := range []struct {
+		schema   string
+		expected error
+	}{
+		{"m" + strings.Repeat(" / index: num", 256), hdsk.ErrSchemaTooManySegments},
+		{"n / index: num", hdsk.ErrBadRoot},
+		{"m / : num", hdsk.ErrInvalidSegment},
+		{"m / index?: num / purpose: any", hdsk.ErrInvalidSegment},
+		{"m / index: int", hdsk.ErrInvalidType},
+		{"m / index: num(1..0)", hdsk.ErrInvalidType},
+	} {
+		if _, err := hdsk.Schema(c.schema); !errors.Is(err, c.expected) {
+			t.Fatalf(`schema %.40q: expected %v, got %v`, c.schema, c.expected, err)
+		}
+	}
+	schema, err := hdsk.Schema("m / application: any / index: num(0..1000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		path     string
+		expected error
+	}{
+		{"n/42/0", hdsk.ErrBadRoot},
+		{"m/42/0/1", hdsk.ErrTooManyIndices},
+		{"m/42/1001", hdsk.ErrIndexOutOfRange},
+		{"m/42/4294967296", hdsk.ErrIndexOutOfRange},
+		{"m//0", hdsk.ErrEmptyIndex},
+		{"m/42/", hdsk.ErrEmptyIndex},
+		{"m/", hdsk.ErrEmptyIndex},
+	} {
+		if _, err := hdsk.Path(h, c.path, schema); !errors.Is(err, c.expected) {
+			t.Fatalf(`path %q: expected %v, got %v`, c.path, c.expected, err)
+		}
+	}
+	if _, err := (hdsk.Params{Hardening: true}).Path(h, "m/42/2147483648", schema); !errors.Is(err, hdsk.ErrIndexOutOfRange) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrIndexOutOfRange, err)
+	}
+	if _, err := hdsk.Path(h, "m/4294967296/0", schema); err != nil {
+		t.Fatalf(`expected numeric index outside of the range of an any segment to be hashed, got %v`, err)
+	}
+	if _, err := hdsk.ParsePathStrict(h, "m/42", schema); !errors.Is(err, hdsk.ErrMissingIndex) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMissingIndex, err)
+	}
+	if _, err := hdsk.Path(h, "m/42/", schema); err == nil || !strings.Contains(err.Error(), "empty index at position 1") {
+		t.Fatalf(`expected error naming the position of the empty index, got %v`, err)
+	}
+}
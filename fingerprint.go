@@ -0,0 +1,82 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// ComputeFingerprint calculates the 16 byte fingerprint of a child key under a parent key, from a
+// given hash, parent key, and child key. The fingerprint is an HMAC of the child key keyed by the
+// parent key, not a plain hash of the child key, so it can only be computed with the parent key.
+func ComputeFingerprint(h func() hash.Hash, parent, child *HDKey) ([]byte, error) {
+	fp, err := utils.Fingerprint(h, parent.Key, child.Key) // Calculate the fingerprint from the parent and child keys
+	if err != nil {
+		return nil, fmt.Errorf(`fingerprint, %w`, err)
+	}
+	return fp, nil // Return the fingerprint
+}
+
+// ExpectedFingerprint derives the fingerprint that the node for a derivation path would have, from
+// a given hash, master key, schema, and derivation path. Fingerprints are deterministic, so a path
+// whose expected fingerprint is absent from a published set of fingerprints was not provisioned.
+func ExpectedFingerprint(h func() hash.Hash, master *HDKey, schema HDSchema, pathStr string) ([]byte, error) {
+	path, err := Path(h, pathStr, schema) // Parse the derivation path
+	if err != nil {
+		return nil, fmt.Errorf(`expected fingerprint, %w`, err)
+	}
+	node, err := Node(h, master, path) // Derive the node for the derivation path
+	if err != nil {
+		return nil, fmt.Errorf(`expected fingerprint, %w`, err)
+	}
+	clear(node.Key) // Wipe the node keys, only the fingerprint is needed
+	clear(node.Code)
+	return node.Fingerprint, nil // Return the fingerprint of the node
+}
+
+// maxSubtreeNodes is the maximum number of nodes in a subtree walked by SubtreeFingerprints.
+const maxSubtreeNodes = 1 << 20
+
+// SubtreeFingerprints derives the fingerprints of every node in a bounded subtree descending from a
+// master key, from a given hash, master key, schema, and count of indices for each level. Nodes
+// are walked depth first, each with indices from 0 up to the count for its level, and the keys
+// of every node are wiped once its descendants are derived.
+func SubtreeFingerprints(h func() hash.Hash, master *HDKey, schema HDSchema, counts []uint32) ([][]byte, error) {
+	if len(counts) > len(schema) {
+		return nil, fmt.Errorf(`subtree cannot exceed %d levels of schema, got %d`, len(schema), len(counts))
+	}
+	total, width := uint64(0), uint64(1) // Count the nodes in the subtree
+	for _, count := range counts {
+		width *= uint64(count) // Number of nodes at the current level
+		total += width
+		if total > maxSubtreeNodes {
+			return nil, fmt.Errorf(`subtree cannot exceed %d nodes`, maxSubtreeNodes)
+		}
+	}
+	fps := make([][]byte, 0, total) // Allocate slice for the fingerprints
+	var walk func(parent *HDKey, level int) error
+	walk = func(parent *HDKey, level int) error {
+		if level == len(counts) {
+			return nil
+		}
+		for index := range counts[level] {
+			node, err := Child(h, parent, index) // Derive the node for the current index
+			if err != nil {
+				return fmt.Errorf(`subtree level %d index %d, %w`, level, index, err)
+			}
+			fps = append(fps, node.Fingerprint) // Collect the fingerprint of the node
+			err = walk(&node, level+1)          // Walk the descendants of the node
+			clear(node.Key)                     // Wipe the node keys
+			clear(node.Code)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(master, 0); err != nil {
+		return nil, err
+	}
+	return fps, nil // Return the fingerprints of the subtree
+}
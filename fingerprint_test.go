@@ -0,0 +1,122 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestExpectedFingerprint is a test for proving membership of derivation paths.
+func TestExpectedFingerprint(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := make([][]byte, 0, 4) // Fingerprints of the provisioned paths
+	for _, v := range vectors[:4] {
+		path, err := hdsk.Path(h, v.path, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := hdsk.Node(h, &master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		published = append(published, node.Fingerprint)
+	}
+	contains := func(fp []byte) bool {
+		for _, p := range published {
+			if bytes.Equal(p, fp) {
+				return true
+			}
+		}
+		return false
+	}
+	fp, err := hdsk.ExpectedFingerprint(h, &master, schema, vectors[2].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contains(fp) {
+		t.Fatalf(`expected fingerprint for provisioned path %s`, vectors[2].path)
+	}
+	fp, err = hdsk.ExpectedFingerprint(h, &master, schema, vectors[8].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contains(fp) {
+		t.Fatalf(`unexpected fingerprint for absent path %s`, vectors[8].path)
+	}
+	expected := master.Clone()
+	if _, err := hdsk.ExpectedFingerprint(h, &master, schema, "m"); err != nil {
+		t.Fatal(err)
+	}
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected fingerprint of the master key must leave the master key unchanged`)
+	}
+}
+
+// TestSubtreeFingerprints is a test for collecting the fingerprints of a subtree.
+func TestSubtreeFingerprints(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fps, err := hdsk.SubtreeFingerprints(h, &master, schema, []uint32{2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fps) != 2+2*3 {
+		t.Fatalf(`expected %d fingerprints, got %d`, 2+2*3, len(fps))
+	}
+	fp, err := hdsk.ExpectedFingerprint(h, &master, schema, "m/1/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range fps {
+		found = found || bytes.Equal(f, fp)
+	}
+	if !found {
+		t.Fatalf(`expected fingerprint of m/1/2 in subtree`)
+	}
+	if _, err := hdsk.SubtreeFingerprints(h, &master, schema, []uint32{1, 1, 1, 1, 1}); err == nil {
+		t.Fatalf(`expected error for subtree deeper than schema`)
+	}
+	if _, err := hdsk.SubtreeFingerprints(h, &master, schema, []uint32{1 << 12, 1 << 12}); err == nil {
+		t.Fatalf(`expected error for subtree exceeding node limit`)
+	}
+}
+
+// TestComputeFingerprint is a test for calculating fingerprints of child keys.
+func TestComputeFingerprint(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := hdsk.ComputeFingerprint(h, &master, &child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fp) != 16 || !bytes.Equal(fp, child.Fingerprint) {
+		t.Fatalf(`expected the 16 byte fingerprint of the child key`)
+	}
+	sibling, err := hdsk.Child(h, &master, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := hdsk.ComputeFingerprint(h, &sibling, &child) // Fingerprint under a key that is not the parent
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other, child.Fingerprint) {
+		t.Fatalf(`expected a distinct fingerprint under a different parent key`)
+	}
+}
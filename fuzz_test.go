@@ -0,0 +1,51 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// FuzzSchema is a fuzz test for parsing schemas without panicking.
+func FuzzSchema(f *testing.F) {
+	for _, seed := range []string{hdsk.DefaultSchema, "m", "m / index", "m / : / :", "m / a?: num(0..1) / b?: str(/x/)", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		schema, err := hdsk.Schema(str)
+		if err != nil {
+			return
+		}
+		if _, err := hdsk.Schema(schema.String()); err != nil {
+			t.Fatalf(`schema %q did not parse back from its string %q, %v`, str, schema.String(), err)
+		}
+	})
+}
+
+// FuzzPath is a fuzz test for parsing derivation paths without panicking.
+func FuzzPath(f *testing.F) {
+	h := sha256.New
+	for _, seed := range []struct{ schema, path string }{
+		{hdsk.DefaultSchema, hdsk.DefaultPath},
+		{hdsk.DefaultSchema, "m/42'/0h/1/0"},
+		{"m / a: hex / b?: any!hex", "m/0a/ff"},
+		{"m / index", "m/0"},
+		{"", ""},
+	} {
+		f.Add(seed.schema, seed.path)
+	}
+	f.Fuzz(func(t *testing.T, schemaStr, pathStr string) {
+		schema, err := hdsk.Schema(schemaStr)
+		if err != nil {
+			return
+		}
+		path, err := hdsk.Path(h, pathStr, schema)
+		if err != nil {
+			return
+		}
+		if len(path) > len(schema) {
+			t.Fatalf(`path %q has more indices than schema %q`, pathStr, schemaStr)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// hardenedVectors are HDSK test vectors for derivation paths with hardened indices.
+var hardenedVectors = []struct {
+	path hdsk.HDPath
+	key  string
+}{
+	{
+		path: hdsk.HDPath{42 | hdsk.Hardened, 0, 1 | hdsk.Hardened, 0},
+		key:  "6998548981b217a2f215097d492f8d00f28bb746bdc997b86cb971f088fcdf5f",
+	},
+	{
+		path: hdsk.HDPath{42 | hdsk.Hardened, 0 | hdsk.Hardened, 1 | hdsk.Hardened, 0 | hdsk.Hardened},
+		key:  "65e950c17ff314483dafd935bf9dcf11968c148f24629e6489de570930bcbe8e",
+	},
+}
+
+// TestHardened is a test for hardened child key derivation.
+func TestHardened(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	p := hdsk.Params{Hardening: true}
+	for _, v := range hardenedVectors {
+		dk, err := p.Node(h, &master, v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dkHex := hex.EncodeToString(dk.Key)
+		if dkHex != v.key {
+			t.Fatalf(`mismatch for %v: expected %q, got %q`, v.path, v.key, dkHex)
+		}
+	}
+	hardened, err := hdsk.HardenedChild(h, &master, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagged, err := p.Child(h, &master, 42|hdsk.Hardened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(hardened.Key, flagged.Key) {
+		t.Fatalf(`hardened child must match child with the hardened flag`)
+	}
+	unhardened, err := hdsk.Child(h, &master, 42|hdsk.Hardened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(hardened.Key, unhardened.Key) {
+		t.Fatalf(`child with the hardened flag must only be hardened in hardened index mode`)
+	}
+	normal, err := hdsk.Child(h, &master, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(hardened.Key, normal.Key) {
+		t.Fatalf(`hardened and normal children must differ`)
+	}
+	lineage, err := hdsk.Lineage(h, &hardened, &master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lineage {
+		t.Fatalf(`invalid lineage for hardened child`)
+	}
+	// Attempt derivation from the chain code alone, with an unknown master key
+	codeOnly := hdsk.HDKey{Key: make([]byte, 32), Code: master.Code, Depth: master.Depth}
+	forged, err := hdsk.Child(h, &codeOnly, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(forged.Key, normal.Key) {
+		t.Fatalf(`normal child must be reproducible from the chain code alone`)
+	}
+	forged, err = hdsk.HardenedChild(h, &codeOnly, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(forged.Key, hardened.Key) {
+		t.Fatalf(`hardened child must not be reproducible from the chain code alone`)
+	}
+	forged, err = hdsk.Child(h, &codeOnly, 42|hdsk.Hardened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(forged.Key, unhardened.Key) {
+		t.Fatalf(`flagged child outside of hardened index mode must be a normal child`)
+	}
+}
+
+// TestNodeHardened is a test for deriving nodes with every index hardened.
+func TestNodeHardened(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 0}
+	node, err := hdsk.NodeHardened(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagged, err := hdsk.Params{Hardening: true}.Node(h, &master, hdsk.HDPath{42 | hdsk.Hardened, 0 | hdsk.Hardened, 1 | hdsk.Hardened, 0 | hdsk.Hardened})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.Equal(&flagged) {
+		t.Fatalf(`expected the same key as Node with every index flagged as hardened`)
+	}
+	normal, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Equal(&normal) {
+		t.Fatalf(`expected a different key than Node without every index flagged`)
+	}
+	if path[0] != 42 {
+		t.Fatalf(`expected the derivation path to be left unchanged`)
+	}
+}
@@ -0,0 +1,83 @@
+package hdsk
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// Clone returns an independent copy of a key, copying the key, chain code, fingerprint, and any
+// retained parent into new slices, so that wiping either key with Zero leaves the other intact.
+func (k *HDKey) Clone() HDKey {
+	clone := HDKey{
+		Key:         bytes.Clone(k.Key),
+		Code:        bytes.Clone(k.Code),
+		Depth:       k.Depth,
+		Fingerprint: bytes.Clone(k.Fingerprint),
+	}
+	if k.parent != nil {
+		clone.parent = &link{key: k.parent.key.Clone(), p: k.parent.p, ctx: bytes.Clone(k.parent.ctx), aad: bytes.Clone(k.parent.aad)}
+	}
+	return clone
+}
+
+// Zero overwrites the key, chain code, fingerprint, and any retained parent of a key with zero
+// bytes in place, and releases them. Zero only wipes the bytes held by the key, and does not
+// protect against copies of the key material made elsewhere, such as by assigning the key or
+// slicing the key material.
+func (k *HDKey) Zero() {
+	if k.parent != nil {
+		k.parent.key.Zero() // Wipe the retained parent
+	}
+	clear(k.Key)         // Overwrite the key with zero bytes
+	clear(k.Code)        // Overwrite the chain code with zero bytes
+	clear(k.Fingerprint) // Overwrite the fingerprint with zero bytes
+	*k = HDKey{}         // Release the key material and reset the depth
+}
+
+// Equal checks if a key is equal to another key, comparing the key, chain code, and fingerprint in
+// constant time, and the depth.
+func (k *HDKey) Equal(other *HDKey) bool {
+	key := subtle.ConstantTimeCompare(k.Key, other.Key)                // Compare the keys in constant time
+	code := subtle.ConstantTimeCompare(k.Code, other.Code)             // Compare the chain codes in constant time
+	fp := subtle.ConstantTimeCompare(k.Fingerprint, other.Fingerprint) // Compare the fingerprints in constant time
+	depth := subtle.ConstantTimeEq(int32(k.Depth), int32(other.Depth)) // Compare the depths in constant time
+	return key&code&fp&depth == 1                                      // Return the result of every comparison
+}
+
+// IsLeaf checks if a key is a leaf of a hierarchy under a given derivation path schema, with a
+// depth equal to the number of segments of the schema. Keys deeper than the schema are not
+// leaves, and are reported by DepthBeyondSchema. Under a schema with a wildcard segment, keys can
+// always derive further, so no key is a leaf.
+func (k *HDKey) IsLeaf(s HDSchema) bool {
+	return !s.wildcard() && uint64(k.Depth) == uint64(s.Len())
+}
+
+// DepthBeyondSchema checks if a key is deeper in a hierarchy than a given derivation path schema
+// allows, with a depth exceeding the number of segments of the schema. Under a schema with a
+// wildcard segment, no key is deeper than the schema.
+func (k *HDKey) DepthBeyondSchema(s HDSchema) bool {
+	return !s.wildcard() && uint64(k.Depth) > uint64(s.Len())
+}
+
+// shortIDLen is the number of bytes of the fingerprint in the short identifier of a key.
+const shortIDLen = 4
+
+// ShortID returns a short identifier of a key for correlating log lines, as the first 4 bytes of
+// the fingerprint hex encoded. The identifier never includes the key or chain code.
+func (k *HDKey) ShortID() string {
+	return hex.EncodeToString(k.Fingerprint[:min(shortIDLen, len(k.Fingerprint))])
+}
+
+// String returns the key as a string of its depth and short identifier, never including the key,
+// chain code, or full fingerprint, so that keys are safe to format with verbs such as %v.
+func (k HDKey) String() string {
+	return fmt.Sprintf(`HDKey{Depth: %d, ID: %s}`, k.Depth, k.ShortID())
+}
+
+// GoString returns the key as a string of its depth and short identifier, as String does, so that
+// formatting keys with the %#v verb is also safe.
+func (k HDKey) GoString() string {
+	return k.String()
+}
@@ -0,0 +1,162 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestZero is a test for wiping key material.
+func TestZero(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, code, fp := child.Key, child.Code, child.Fingerprint
+	child.Zero()
+	if child.Key != nil || child.Code != nil || child.Fingerprint != nil || child.Depth != 0 {
+		t.Fatalf(`expected zero value key after wiping`)
+	}
+	for _, b := range [][]byte{key, code, fp} {
+		for _, v := range b {
+			if v != 0 {
+				t.Fatalf(`expected key material to be overwritten with zero bytes`)
+			}
+		}
+	}
+	var empty hdsk.HDKey
+	empty.Zero() // Wiping a zero value key must not panic
+}
+
+// TestEqual is a test for comparing keys.
+func TestEqual(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	a, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Equal(&b) {
+		t.Fatalf(`expected keys derived identically to be equal`)
+	}
+	depth := b
+	depth.Depth++
+	if a.Equal(&depth) {
+		t.Fatalf(`expected keys differing in depth to differ`)
+	}
+	for _, field := range []func(k *hdsk.HDKey) []byte{
+		func(k *hdsk.HDKey) []byte { return k.Key },
+		func(k *hdsk.HDKey) []byte { return k.Code },
+		func(k *hdsk.HDKey) []byte { return k.Fingerprint },
+	} {
+		c, err := hdsk.Child(h, &master, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		field(&c)[0] ^= 0x01 // Flip a bit in a single byte
+		if a.Equal(&c) {
+			t.Fatalf(`expected keys differing by one byte to differ`)
+		}
+	}
+}
+
+// TestClone is a test for copying keys.
+func TestClone(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := child.Clone()
+	if !clone.Equal(&child) {
+		t.Fatalf(`expected the clone to equal the key`)
+	}
+	clone.Key[0] ^= 0xFF
+	clone.Code[0] ^= 0xFF
+	clone.Fingerprint[0] ^= 0xFF
+	if clone.Equal(&child) {
+		t.Fatalf(`expected the mutated clone to differ from the key`)
+	}
+	again, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !child.Equal(&again) {
+		t.Fatalf(`expected the key to be unaffected by mutating the clone`)
+	}
+	clone.Zero()
+	if !child.Equal(&again) {
+		t.Fatalf(`expected the key to be unaffected by wiping the clone`)
+	}
+}
+
+// TestIsLeaf is a test for checking if keys are leaves under a schema.
+func TestIsLeaf(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := testMaster(t, h)
+	for _, test := range []struct {
+		path         hdsk.HDPath
+		leaf, beyond bool
+	}{
+		{nil, false, false},
+		{hdsk.HDPath{42, 0}, false, false},
+		{hdsk.HDPath{42, 0, 1, 0}, true, false},
+		{hdsk.HDPath{42, 0, 1, 0, 7}, false, true},
+	} {
+		node := master
+		if len(test.path) > 0 {
+			if node, err = hdsk.Node(h, &master, test.path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if node.IsLeaf(schema) != test.leaf {
+			t.Fatalf(`depth %d: expected leaf %t`, node.Depth, test.leaf)
+		}
+		if node.DepthBeyondSchema(schema) != test.beyond {
+			t.Fatalf(`depth %d: expected beyond schema %t`, node.Depth, test.beyond)
+		}
+	}
+}
+
+// TestString is a test for formatting keys without key material.
+func TestString(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := node.ShortID()
+	if id != hex.EncodeToString(node.Fingerprint[:4]) {
+		t.Fatalf(`expected short identifier from the fingerprint, got %q`, id)
+	}
+	for _, str := range []string{node.String(), fmt.Sprintf("%v", node), fmt.Sprintf("%+v", &node), fmt.Sprintf("%#v", node)} {
+		if !strings.Contains(str, id) || !strings.Contains(str, "2") {
+			t.Fatalf(`expected depth and short identifier in %q`, str)
+		}
+		for _, secret := range [][]byte{node.Key, node.Code, node.Fingerprint} {
+			if strings.Contains(str, hex.EncodeToString(secret)) || strings.Contains(str, fmt.Sprint(secret)) || strings.Contains(str, string(secret)) {
+				t.Fatalf(`key material exposed in %q`, str)
+			}
+		}
+	}
+	var zero hdsk.HDKey
+	if zero.ShortID() != "" {
+		t.Fatalf(`expected empty short identifier for the zero key`)
+	}
+}
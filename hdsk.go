@@ -4,11 +4,12 @@
 package hdsk
 
 import (
+	"bytes"
 	"crypto/hkdf"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"hash"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,76 +28,345 @@ type HDKey struct {
 	Code        []byte // Chain code.
 	Depth       uint32 // Depth in hierarchy.
 	Fingerprint []byte // Key fingerprint.
+	parent      *link  // Retained parent for deriving siblings, see Params.RetainParent.
 }
 
+// link holds a retained copy of the parent of a key, with the parameters, context, and associated
+// data the key was derived with, so that siblings of the key are derived identically.
+type link struct {
+	key      HDKey  // Copy of the parent key.
+	p        Params // Parameters the key was derived with.
+	ctx, aad []byte // Context and associated data the key was derived with.
+}
+
+// Params holds parameters for key derivation. The zero value derives keys identically to the
+// package-level functions. An application domain scopes the keys of an application, so that
+// applications sharing a secret derive distinct hierarchies.
+//
+// By default, keys and chain codes are the first and last bytes of a single HKDF expand, so that
+// each is an adjacent output of the same stream. The split expand mode expands keys and chain
+// codes with distinct info labels from the same pseudorandom key, so that neither output is a
+// continuation of the other, and each is bound to its role. The split expand mode derives
+// different keys than the default mode, and has its own test vectors.
+//
+// By default, child indices are encoded in HKDF info as decimal strings, so that the encodings of
+// indices vary in length and the encoding of one index is a prefix of others, such as 1 and 12.
+// The binary index mode encodes child indices as 4 big endian bytes, matching the encoding of
+// indices in salts, so that every encoding has a fixed length. The binary index mode is a new
+// version of child derivation, deriving different child keys than the default mode, and has its
+// own test vectors.
+//
+// By default, every index derives a normal child key, and parsed indices span 32 bits. The
+// hardened index mode derives hardened child keys for indices flagged by Hardened, and limits
+// parsed indices to 31 bits, with string indices hashed to 31 bit integers, so that only indices
+// with a hardened marker are flagged. The hardened index mode derives different child keys than
+// the default mode for indices with the highest bit set, and parses different indices for string
+// indices whose hash has the highest bit set.
+//
+// Child keys derived with RetainParent set hold a copy of their parent key, so that siblings are
+// derived with Sibling without deriving the parent again. The parent key and chain code are kept
+// in memory for as long as the child, doubling its key material, and anyone holding the child
+// can derive its parent's other children, including hardened children. Only the direct parent is
+// retained, and Zero wipes the retained parent along with the key.
+type Params struct {
+	Separate     bool   // Insert the separator between components of HKDF info, including that of key helpers such as Params.SubKey.
+	Separator    byte   // Separator between components of HKDF info, 0x00 by default.
+	AllowWeak    bool   // Allow master keys from known weak secrets.
+	KeyLen       int    // Length of derived keys in bytes, 32 by default.
+	CodeLen      int    // Length of derived chain codes in bytes, 32 by default.
+	Domain       string // Application domain of up to 255 bytes mixed into HKDF info and salts, none by default.
+	SaltLen      int    // Length of salts in bytes, from 16 up to the size of the hash, 16 by default.
+	SplitExpand  bool   // Expand keys and chain codes separately with KEY and CODE info, see Params.
+	RetainParent bool   // Retain a copy of the parent of child keys for deriving siblings, see Params.
+	BinaryIndex  bool   // Encode child indices in HKDF info as 4 big endian bytes, see Params.
+	Hardening    bool   // Derive hardened child keys for indices flagged by Hardened, see Params.
+}
+
+// Default lengths of derived keys and chain codes, and the length of fingerprints, in bytes.
+const (
+	KeyLen         = 32                   // Length of derived keys.
+	CodeLen        = 32                   // Length of derived chain codes.
+	FingerprintLen = utils.FingerprintLen // Length of fingerprints.
+)
+
+// MaxDepth is the maximum depth of derived keys, matching the 255 indices of the longest
+// derivation path a schema of at most 256 segments, including the root, describes. Child keys are
+// not derived from keys at the maximum depth, and decoded keys may not exceed it.
+const MaxDepth = 255
+
+// Hardened is the flag marking an index for hardened child key derivation. With Params.Hardening
+// set, indices with the flag set derive hardened child keys, and indices without it derive normal
+// child keys.
+const Hardened uint32 = 0x80000000
+
 // DefaultSchema is the default derivation path schema.
 const DefaultSchema string = "m / application: any / purpose: any / context: any / index: num"
 
 // DefaultPath is the default derivation path.
 const DefaultPath string = "m/42/0/1/0"
 
-// Schema parses a new derivation path schema from a given string.
+// Schema parses a new derivation path schema from a given string, with a unique label for each
+// segment. Segments with a label ending in "?" are optional, and may only be followed by other
+// optional segments. A final segment with a type ending in "*", such as "tail: num*", is a
+// wildcard segment, taking any number of indices of its type, including none. Numeric segments
+// may bound their indices to an inclusive range, such as "index: num(0..1000)", and string
+// segments may require their indices to match a pattern, such as "application: str(/^[a-z]+$/)".
+// Segments of the "hex" type take hexadecimal indices, and segments of the "any!hex" type take
+// numeric, hexadecimal, or string indices. Segments of the "any!str" type hash numeric indices
+// that are not in canonical form, such as "007", as strings.
+//
+// Segments are separated by " / ", and schemas with other whitespace around the slashes, such as
+// "m/index: num", are parsed as by SchemaSep with a "/" separator. Schemas in the " / " form
+// parse identically to SchemaSep only where their patterns have no unbalanced parentheses.
 func Schema(str string) (HDSchema, error) {
-	segments := strings.Split(str, " / ")
+	schema, err := parseSchema(strings.Split(str, " / ")) // Parse the segments separated by " / "
+	if err == nil {
+		return schema, nil
+	}
+	if tolerant, terr := SchemaSep(str, "/"); terr == nil {
+		return tolerant, nil // Return the schema with other whitespace around the slashes
+	}
+	return nil, err
+}
+
+// SchemaSep parses a new derivation path schema from a given string and segment separator, like
+// Schema. Whitespace around each segment is trimmed, and separators within the parentheses of
+// type arguments, such as the slashes of patterns, do not separate segments.
+func SchemaSep(str, sep string) (HDSchema, error) {
+	if sep == "" {
+		return nil, fmt.Errorf(`schema separator cannot be empty, %w`, ErrInvalidSegment)
+	}
+	return parseSchema(splitSchema(str, sep))
+}
+
+// parseSchema parses a new derivation path schema from given segments, beginning with the root.
+func parseSchema(segments []string) (HDSchema, error) {
 	if len(segments) > 256 {
-		return nil, fmt.Errorf(`schema cannot exceed 256 segments, got %d`, len(segments))
+		return nil, fmt.Errorf(`schema cannot exceed 256 segments, got %d, %w`, len(segments), ErrSchemaTooManySegments)
 	}
 	if segments[0] != "m" {
-		return nil, fmt.Errorf(`schema must begin with %q, got %q`, "m", segments[0])
+		return nil, fmt.Errorf(`schema must begin with %q, got %q, %w`, "m", segments[0], ErrBadRoot)
 	}
-	allowed := map[string]bool{"str": true, "num": true, "any": true} // Allow strings, numbers, or either
-	result := make([][2]string, 0, len(segments)-1)                   // Allocate slice for the parsed schema
+	pairs := make([][2]string, 0, len(segments)-1) // Allocate slice for the labels and types
 	for _, segment := range segments[1:] {
-		parts := strings.Split(segment, ":") // Split each segment into two parts
-		label := strings.TrimSpace(parts[0]) // Extract the label from the first part
-		typ := strings.TrimSpace(parts[1])   // Extract the type from the second part
-		if label == "" || typ == "" {
-			return nil, fmt.Errorf(`invalid segment in schema, %q`, segment)
+		label, typ, ok := strings.Cut(segment, ":") // Split each segment into a label and type at the first colon
+		if !ok {
+			return nil, fmt.Errorf(`segment %q in schema missing %q separator, %w`, segment, ":", ErrInvalidSegment)
+		}
+		pairs = append(pairs, [2]string{label, typ})
+	}
+	return buildSchema(pairs)
+}
+
+// buildSchema validates a new derivation path schema from given labels and types, with labels
+// ending in "?" marking optional segments.
+func buildSchema(pairs [][2]string) (HDSchema, error) {
+	result := make([][2]string, 0, len(pairs))  // Allocate slice for the parsed schema
+	labels := make(map[string]bool, len(pairs)) // Labels of the parsed segments
+	for i, pair := range pairs {
+		label, typ, err := schemaSegment(pair, i == len(pairs)-1) // Validate the label and type of each segment
+		if err != nil {
+			return nil, err
 		}
-		if !allowed[typ] {
-			return nil, fmt.Errorf(`invalid type %q for label %q in schema`, typ, label)
+		if labels[label] {
+			return nil, fmt.Errorf(`duplicate label %q in schema, %w`, label, ErrInvalidSegment)
 		}
+		labels[label] = true
 		result = append(result, [2]string{label, typ}) // Add the label and type to the parsed results
 	}
+	if _, err := HDSchema(result).required(); err != nil {
+		return nil, err
+	}
 	return result, nil // Return the parsed schema
 }
 
-// Path parses a new derivation path from a given hash, string, and schema.
+// schemaSegment validates the label and type of a segment of a derivation path schema, from a
+// given label and type, and whether the segment is the final segment. The type of the segment is
+// returned with the "*" marker of wildcard segments, followed by the "?" marker of optional
+// segments.
+func schemaSegment(pair [2]string, last bool) (string, string, error) {
+	label, typ := strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]) // Trim the label and type
+	label, opt := strings.CutSuffix(label, "?")                          // Remove the optional marker from the label
+	typ, wild := strings.CutSuffix(typ, "*")                             // Remove the wildcard marker from the type
+	if label == "" || typ == "" {
+		return "", "", fmt.Errorf(`invalid segment in schema, %q, %w`, pair[0]+":"+pair[1], ErrInvalidSegment)
+	}
+	if wild && (opt || !last) {
+		return "", "", fmt.Errorf(`wildcard segment %q must be the final segment and cannot be optional, %w`, label, ErrInvalidSegment)
+	}
+	if err := utils.ValidateType(typ); err != nil {
+		return "", "", fmt.Errorf(`invalid type %q for label %q in schema, %w, %w`, typ, label, ErrInvalidType, err)
+	}
+	if wild {
+		typ += "*" // Mark the type of wildcard segments
+	}
+	if opt {
+		typ += "?" // Mark the type of optional segments
+	}
+	return label, typ, nil
+}
+
+// Path parses a new derivation path from a given hash, string, and schema. The hash is only used
+// to map string indices to integers, and need not be the hash used to derive keys. A derivation
+// path may omit trailing indices, unless the schema marks segments as optional, in which case
+// only the optional segments may be omitted. Segments for secret indices, such as secret labels,
+// should be of the plain "str" type, the only type whose indices are hashed without branching on
+// their contents. Hardened markers are only parsed with Params.Path, see Params.
 func Path(h func() hash.Hash, str string, schema HDSchema) (HDPath, error) {
+	return Params{}.Path(h, str, schema)
+}
+
+// Path parses a new derivation path from a given hash, string, and schema, like Path, using the
+// parameters. In hardened index mode, indices are limited to 31 bits, numeric indices followed by
+// an apostrophe, such as "42'", are parsed as hardened indices, and so are indices of numeric
+// types followed by "h", such as "42h". Indices of secret labels must not end in an apostrophe in
+// hardened index mode.
+func (p Params) Path(h func() hash.Hash, str string, schema HDSchema) (HDPath, error) {
+	getIndex, flag := p.parser()
+	return parsePath(h, str, schema, getIndex, flag)
+}
+
+// parser returns the index parser and hardened flag of derivation paths, parsing 32 bit indices
+// without hardened markers by default, or 31 bit indices with hardened markers in hardened index
+// mode.
+func (p Params) parser() (func(func() hash.Hash, string, string) (uint32, error), uint32) {
+	if !p.Hardening {
+		return utils.GetIndex, 0 // Parse 32 bit indices without hardened markers
+	}
+	return utils.GetIndex31, Hardened // Parse 31 bit indices with hardened markers
+}
+
+// parsePath parses a new derivation path of 32 or 64 bit indices from a given hash, string,
+// schema, index parser, and hardened flag, or a flag of 0 to parse indices without hardened markers.
+func parsePath[T uint32 | uint64](h func() hash.Hash, str string, schema HDSchema, getIndex func(func() hash.Hash, string, string) (T, error), flag T) ([]T, error) {
+	result := make([]T, 0, strings.Count(str, "/")) // Allocate slice for the parsed path
+	err := walkPath(h, str, schema, getIndex, flag, func(_, _ string, idx T) {
+		result = append(result, idx) // Add the parsed index to the result
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil // Return the parsed derivation path
+}
+
+// walkPath parses each index of a derivation path of 32 or 64 bit indices from a given hash,
+// string, schema, index parser, and hardened flag, calling a given function with the label, raw
+// string, and parsed value of each index in order.
+func walkPath[T uint32 | uint64](h func() hash.Hash, str string, schema HDSchema, getIndex func(func() hash.Hash, string, string) (T, error), flag T, fn func(label, raw string, idx T)) error {
 	segments := strings.Split(str, "/")
 	if len(segments) == 0 || segments[0] != "m" {
-		return nil, fmt.Errorf(`derivation path must begin with %q, got %q`, "m", segments[0])
+		return fmt.Errorf(`derivation path must begin with %q, got %q, %w`, "m", segments[0], ErrBadRoot)
 	}
 	indices := segments[1:] // Define indices as elements starting at index 1
-	if len(indices) > len(schema) {
-		return nil, fmt.Errorf(`too many indices in derivation path: got %d, expected %d`, len(indices), len(schema))
+	if len(indices) > schema.capacity() {
+		return fmt.Errorf(`too many indices in derivation path: got %d, expected %d, %w`, len(indices), len(schema), ErrTooManyIndices)
+	}
+	required, err := schema.required() // Get the number of required indices from the schema
+	if err != nil {
+		return err
+	}
+	if len(indices) < required {
+		return fmt.Errorf(`missing required index for label %q in derivation path, %w`, schema[len(indices)][0], ErrMissingIndex)
 	}
-	result := make(HDPath, 0, len(indices)) // Allocate slice for the parsed path
 	for i, index := range indices {
-		label, typ := schema[i][0], schema[i][1]  // Get label and type for the current index from the schema
-		idx, err := utils.GetIndex(h, index, typ) // Parse the current index, enforcing the type from the schema
+		label, typ := schema.segment(i) // Get label and type for the current index from the schema
+		if index == "" {
+			return fmt.Errorf(`empty index at position %d label %q, %w`, i, label, ErrEmptyIndex)
+		}
+		idx, err := parseIndex(h, index, typ, getIndex, flag) // Parse the current index, enforcing the type from the schema
 		if err != nil {
-			return nil, fmt.Errorf(`derivation path position %d label %q, %w`, i, label, err)
+			return fmt.Errorf(`derivation path position %d label %q, %w`, i, label, err)
 		}
-		result = append(result, idx) // Add the parsed index to the result
+		fn(label, index, idx) // Pass the parsed index to the function
 	}
-	return result, nil // Return the parsed derivation path
+	return nil
 }
 
-// Master derives a new master key from a given hash and secret.
+// parseIndex parses an index of a derivation path from a given hash, index string, type, index
+// parser, and hardened flag, setting the flag on indices with a hardened marker. Hardened markers
+// are left in indices for a flag of 0.
+func parseIndex[T uint32 | uint64](h func() hash.Hash, index, typ string, getIndex func(func() hash.Hash, string, string) (T, error), flag T) (T, error) {
+	hardened := false
+	if flag != 0 {
+		var err error
+		index, hardened, err = cutHardened(index, typ) // Remove the hardened marker from the index
+		if err != nil {
+			return 0, err
+		}
+	}
+	idx, err := getIndex(h, index, typ) // Parse the index, enforcing the type
+	if err != nil {
+		return 0, err
+	}
+	if hardened {
+		idx |= flag // Flag the index as hardened
+	}
+	return idx, nil
+}
+
+// ParsePathStrict parses a new derivation path from a given hash, string, and schema, like Path,
+// but requires an index for every segment of the schema, including optional segments.
+func ParsePathStrict(h func() hash.Hash, str string, schema HDSchema) (HDPath, error) {
+	path, err := Path(h, str, schema) // Parse the derivation path
+	if err != nil {
+		return nil, err
+	}
+	if remaining := path.Remaining(schema); remaining > 0 {
+		return nil, fmt.Errorf(`missing %d indices in derivation path, first for label %q, %w`, remaining, schema[len(path)][0], ErrMissingIndex)
+	}
+	return path, nil // Return the parsed derivation path
+}
+
+// Master derives a new master key from a given hash and secret. The hash is used for all key
+// derivation, and the same hash must be used to derive children of the master key. Known weak
+// secrets are refused with ErrKnownWeakSecret, see Params to allow them.
 func Master(h func() hash.Hash, secret []byte) (HDKey, error) {
-	salt, err := utils.CalcSalt(h, secret, nil) // Derive salt from the secret
+	return Params{}.Master(h, secret)
+}
+
+// weak checks if a secret is a known weak secret. Known weak secrets are empty secrets, and secrets
+// where every byte is 0x00 (such as the secret of the test vectors) or every byte is 0xFF.
+func weak(secret []byte) bool {
+	if len(secret) == 0 {
+		return true
+	}
+	for _, b := range []byte{0x00, 0xFF} {
+		if bytes.Count(secret, []byte{b}) == len(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// Master derives a new master key from a given hash and secret, using the parameters.
+// Intermediate key material is wiped before returning an error.
+func (p Params) Master(h func() hash.Hash, secret []byte) (HDKey, error) {
+	if !p.AllowWeak && weak(secret) {
+		return HDKey{}, fmt.Errorf(`master key secret, %w`, ErrKnownWeakSecret)
+	}
+	keyLen, codeLen, err := p.lengths() // Get the lengths of the key and chain code
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`master key, %w`, err)
+	}
+	domain, err := p.domain() // Get the application domain
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`master key, %w`, err)
+	}
+	salt, err := utils.CalcSaltDomain(h, secret, nil, domain, p.saltLen()) // Derive salt from the secret
 	if err != nil {
 		return HDKey{}, fmt.Errorf(`master key salt, %w`, err)
 	}
-	ikm, err := hkdf.Key(h, secret, salt, "MASTER", 64) // Derive ikm from secret
+	ikm, err := p.expand(h, secret, salt, string(domain)+"MASTER", keyLen, codeLen) // Derive ikm from secret
 	if err != nil {
+		clear(salt) // Wipe the salt
 		return HDKey{}, fmt.Errorf(`master key hkdf, %w`, err)
 	}
-	master := ikm[:32]                              // First 32 bytes as the key
-	code := ikm[32:64]                              // Last 32 bytes as the chain code
+	master := ikm[:keyLen]                          // First bytes as the key
+	code := ikm[keyLen:]                            // Last bytes as the chain code
 	fp, err := utils.Fingerprint(h, secret, master) // Derive a fingerprint for the master key
 	if err != nil {
+		clear(ikm)  // Wipe the key and chain code
+		clear(salt) // Wipe the salt
 		return HDKey{}, fmt.Errorf(`master key fingerprint, %w`, err)
 	}
 	key := HDKey{
@@ -110,21 +380,135 @@ func Master(h func() hash.Hash, secret []byte) (HDKey, error) {
 
 // Child derives a new child key from a given hash, master key, and index.
 func Child(h func() hash.Hash, master *HDKey, index uint32) (HDKey, error) {
+	return Params{}.Child(h, master, index)
+}
+
+// Child derives a new child key from a given hash, master key, and index, using the parameters.
+func (p Params) Child(h func() hash.Hash, master *HDKey, index uint32) (HDKey, error) {
+	return p.child(h, master, index, nil, nil)
+}
+
+// HardenedChild derives a new hardened child key from a given hash, master key, and index, and
+// is equivalent to Child in hardened index mode with the Hardened flag set on the index.
+func HardenedChild(h func() hash.Hash, master *HDKey, index uint32) (HDKey, error) {
+	return Params{Hardening: true}.Child(h, master, index|Hardened)
+}
+
+// child derives a new child key from a given hash, master key, index, context mixed into the HKDF
+// info, and associated data folded into the fingerprint, using the parameters. Normal child keys
+// are derived from the master chain code alone, while hardened child keys fold the master key
+// into the salt and ikm, so they cannot be derived from the chain code alone. Intermediate key
+// material is wiped before returning an error.
+func (p Params) child(h func() hash.Hash, master *HDKey, index uint32, ctx, aad []byte) (HDKey, error) {
+	info1, hardened, info2 := p.childInfo(index, ctx)
+	key, err := p.derive(h, master, hardened, info1, info2, aad)
+	if err != nil {
+		return HDKey{}, err
+	}
+	p.retain(&key, master, ctx, aad)
+	return key, nil // Return the child HD key
+}
+
+// retain retains a copy of the parent of a child key when enabled by the parameters, from a given
+// child key, parent key, and the context and associated data the child was derived with. The
+// parent of the parent is not retained.
+func (p Params) retain(key, parent *HDKey, ctx, aad []byte) {
+	if !p.RetainParent {
+		return
+	}
+	copied := HDKey{
+		Key:         bytes.Clone(parent.Key),
+		Code:        bytes.Clone(parent.Code),
+		Depth:       parent.Depth,
+		Fingerprint: bytes.Clone(parent.Fingerprint),
+	}
+	key.parent = &link{key: copied, p: p, ctx: bytes.Clone(ctx), aad: bytes.Clone(aad)}
+}
+
+// childInfo returns the salt context info, whether the child is hardened, and the HKDF info of a
+// child key from a given index and context mixed into the HKDF info, using the parameters.
+func (p Params) childInfo(index uint32, ctx []byte) ([]byte, bool, string) {
 	info1 := make([]byte, 4)
-	binary.BigEndian.PutUint32(info1, index)           // Context info from bytes of encoded index
-	salt, err := utils.CalcSalt(h, master.Code, info1) // Derive salt from the master code
+	binary.BigEndian.PutUint32(info1, index)       // Context info from bytes of encoded index
+	hardened := p.Hardening && index&Hardened != 0 // Only flagged indices in hardened index mode are hardened
+	tag := "CHILD"
+	if hardened {
+		tag, index = "HARDENED", index&^Hardened // Encode hardened indices without the flag
+	}
+	info2 := p.info(tag, p.index(index)) // Construct info for HKDF from CHILD or HARDENED + index encoding
+	if len(ctx) > 0 {
+		info2 += "\x00CONTEXT" + string(ctx) // Mix the context into the info, terminating the index string
+	}
+	return info1, hardened, info2
+}
+
+// derive derives a new child key from a given hash, master key, whether the child is hardened,
+// salt context info, HKDF info, and associated data folded into the fingerprint, using the
+// parameters.
+func (p Params) derive(h func() hash.Hash, master *HDKey, hardened bool, info1 []byte, info2 string, aad []byte) (HDKey, error) {
+	parent, err := p.prepare(h, master) // Validate the master key and prepare the parameters
+	if err != nil {
+		return HDKey{}, err
+	}
+	return parent.derive(hardened, info1, info2, aad)
+}
+
+// parentKey holds a parent key, with the lengths and application domain of the parameters
+// prepared for deriving its children.
+type parentKey struct {
+	p       Params           // Parameters for key derivation.
+	h       func() hash.Hash // Hash for key derivation.
+	master  *HDKey           // Parent key of the derived children.
+	keyLen  int              // Length of derived keys.
+	codeLen int              // Length of derived chain codes.
+	domain  []byte           // Length prefixed application domain.
+}
+
+// prepare validates a parent key from a given hash and master key, and prepares the lengths and
+// application domain of the parameters for deriving its children.
+func (p Params) prepare(h func() hash.Hash, master *HDKey) (parentKey, error) {
+	keyLen, codeLen, err := p.lengths() // Get the lengths of the key and chain code
+	if err != nil {
+		return parentKey{}, fmt.Errorf(`child key, %w`, err)
+	}
+	if err := p.check(master); err != nil {
+		return parentKey{}, fmt.Errorf(`child key, %w`, err)
+	}
+	if master.Depth >= MaxDepth {
+		return parentKey{}, fmt.Errorf(`child key of key at depth %d, %w`, master.Depth, ErrMaxDepth)
+	}
+	domain, err := p.domain() // Get the application domain
+	if err != nil {
+		return parentKey{}, fmt.Errorf(`child key, %w`, err)
+	}
+	return parentKey{p: p, h: h, master: master, keyLen: keyLen, codeLen: codeLen, domain: domain}, nil
+}
+
+// derive derives a new child key of the parent key from whether the child is hardened, salt
+// context info, HKDF info, and associated data folded into the fingerprint.
+func (b parentKey) derive(hardened bool, info1 []byte, info2 string, aad []byte) (HDKey, error) {
+	h, master := b.h, b.master
+	secret, salted := master.Code, master.Code // Normal child keys derive from the master chain code
+	if hardened {
+		// Hardened child keys derive from the master key and chain code
+		secret, salted = slices.Concat(master.Key, master.Code), master.Key
+		defer clear(secret) // Wipe the concatenated key and chain code
+	}
+	salt, err := utils.CalcSaltDomain(h, salted, info1, b.domain, b.p.saltLen()) // Derive salt from the master code, or key if hardened
 	if err != nil {
 		return HDKey{}, fmt.Errorf(`child key salt, %w`, err)
 	}
-	info2 := "CHILD" + strconv.Itoa(int(index))           // Construct info for HKDF form CHILD + index string
-	ikm, err := hkdf.Key(h, master.Code, salt, info2, 64) // Derive ikm from master chain code
+	ikm, err := b.p.expand(h, secret, salt, string(b.domain)+info2, b.keyLen, b.codeLen) // Derive ikm from master chain code, and key if hardened
 	if err != nil {
+		clear(salt) // Wipe the salt
 		return HDKey{}, fmt.Errorf(`child key hkdf, %w`, err)
 	}
-	child := ikm[:32]                                  // First 32 bytes as the key
-	code := ikm[32:64]                                 // Last 32 bytes as the chain code
-	fp, err := utils.Fingerprint(h, master.Key, child) // Derive a fingerprint for the child key
+	child := ikm[:b.keyLen]                                    // First bytes as the key
+	code := ikm[b.keyLen:]                                     // Last bytes as the chain code
+	fp, err := utils.FingerprintAAD(h, master.Key, child, aad) // Derive a fingerprint for the child key
 	if err != nil {
+		clear(ikm)  // Wipe the key and chain code
+		clear(salt) // Wipe the salt
 		return HDKey{}, fmt.Errorf(`child key fingerprint, %w`, err)
 	}
 	key := HDKey{
@@ -137,35 +521,218 @@ func Child(h func() hash.Hash, master *HDKey, index uint32) (HDKey, error) {
 }
 
 // Node derives a new key at a node in a hierarchy descending from a master key, from a given
-// hash, master key, and derivation path.
+// hash, master key, and derivation path. An empty derivation path, such as parsed from "m",
+// returns a copy of the master key, so that wiping the returned key leaves the master key intact.
 func Node(h func() hash.Hash, master *HDKey, path HDPath) (HDKey, error) {
-	key, err := Child(h, master, path[0]) // Initialize key with first index from the path
+	return Params{}.Node(h, master, path)
+}
+
+// Node derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, and derivation path, using the parameters.
+func (p Params) Node(h func() hash.Hash, master *HDKey, path HDPath) (HDKey, error) {
+	return p.node(h, master, path, nil, nil)
+}
+
+// DerivePath derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, derivation path string, and schema. The derivation path is parsed with Path
+// and the key is derived with Node, using the same hash for both.
+func DerivePath(h func() hash.Hash, master *HDKey, str string, schema HDSchema) (HDKey, error) {
+	_, key, err := DeriveFromString(h, master, str, schema)
+	return key, err
+}
+
+// DeriveFromString derives a new key at a node in a hierarchy descending from a master key, from a
+// given hash, master key, derivation path string, and schema, like DerivePath, also returning the
+// parsed derivation path. Errors from parsing and deriving are wrapped distinctly.
+func DeriveFromString(h func() hash.Hash, master *HDKey, str string, schema HDSchema) (HDPath, HDKey, error) {
+	path, err := Path(h, str, schema) // Parse the derivation path
+	if err != nil {
+		return nil, HDKey{}, fmt.Errorf(`derive path parsing, %w`, err)
+	}
+	key, err := Node(h, master, path) // Derive the node for the derivation path
 	if err != nil {
-		return HDKey{}, fmt.Errorf(`node initialization, %w`, err)
+		return nil, HDKey{}, fmt.Errorf(`derive path derivation, %w`, err)
+	}
+	return path, key, nil // Return the derivation path and HD key
+}
+
+// NodeHardened derives a new key at a node in a hierarchy descending from a master key, from a
+// given hash, master key, and derivation path, deriving a hardened child key at every index
+// whether or not the index is flagged as hardened. The derived key is identical to a key derived
+// with Node in hardened index mode when every index is flagged, and differs from a key derived
+// with Node otherwise.
+func NodeHardened(h func() hash.Hash, master *HDKey, path HDPath) (HDKey, error) {
+	hardened := make(HDPath, len(path)) // Allocate a copy of the derivation path
+	for i, index := range path {
+		hardened[i] = index | Hardened // Flag each index as hardened
+	}
+	return Params{Hardening: true}.Node(h, master, hardened)
+}
+
+// node derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, derivation path, context mixed into every HKDF info, and associated data
+// folded into every fingerprint, using the parameters.
+func (p Params) node(h func() hash.Hash, master *HDKey, path HDPath, ctx, aad []byte) (HDKey, error) {
+	return p.walk(master, len(path), func(parent *HDKey, i int) (HDKey, error) {
+		return p.child(h, parent, path[i], ctx, aad) // Derive a child of the parent for the current index
+	}, nil)
+}
+
+// walk derives a new key at a node in a hierarchy descending from a master key, from a given
+// master key, length of the derivation path, function deriving the child of a parent key for
+// each position of the path, and optional function called with the key at each level, using the
+// parameters. The master key and the depth of the node are checked before any derivation, and an
+// empty derivation path returns a copy of the master key.
+func (p Params) walk(master *HDKey, n int, child func(parent *HDKey, i int) (HDKey, error), fn func(level *HDKey)) (HDKey, error) {
+	if err := p.check(master); err != nil {
+		return HDKey{}, fmt.Errorf(`node, %w`, err)
+	}
+	if uint64(master.Depth)+uint64(n) > MaxDepth {
+		return HDKey{}, fmt.Errorf(`node at depth %d, %w`, uint64(master.Depth)+uint64(n), ErrMaxDepth)
 	}
-	for i := 1; i < len(path); i++ {
-		index := path[i]                 // Get the current index
-		key, err = Child(h, &key, index) // Derive a child of key for the current index
+	if n == 0 {
+		return master.Clone(), nil // Return a copy of the master key for an empty path
+	}
+	var key HDKey
+	parent := master // Begin at the master key
+	for i := range n {
+		next, err := child(parent, i) // Derive a child of the parent for the current position
 		if err != nil {
-			return HDKey{}, fmt.Errorf(`node derivation, %w`, err)
+			return HDKey{}, fmt.Errorf(`node position %d, %w`, i, err)
+		}
+		if fn != nil {
+			fn(&next) // Pass the key at the current level to the function
 		}
+		key = next
+		parent = &key
 	}
 	return key, nil // Return the HD key
 }
 
+// lengths returns the lengths of derived keys and chain codes, which must be between 16 and 255
+// bytes, using 32 bytes for lengths left unset.
+func (p Params) lengths() (int, int, error) {
+	keyLen, codeLen := p.KeyLen, p.CodeLen
+	if keyLen == 0 {
+		keyLen = KeyLen
+	}
+	if codeLen == 0 {
+		codeLen = CodeLen
+	}
+	if keyLen < 16 || keyLen > 255 || codeLen < 16 || codeLen > 255 {
+		return 0, 0, fmt.Errorf(`key and chain code lengths must be between 16 and 255 bytes, got %d and %d`, keyLen, codeLen)
+	}
+	return keyLen, codeLen, nil
+}
+
+// expand derives the key and chain code as ikm from a given hash, secret, salt, HKDF info, and
+// lengths of the key and chain code. In split expand mode the key and chain code are expanded separately
+// from the same pseudorandom key, with KEY and CODE appended to the info.
+func (p Params) expand(h func() hash.Hash, secret, salt []byte, info string, keyLen, codeLen int) ([]byte, error) {
+	if !p.SplitExpand {
+		if err := checkExpand(h, keyLen+codeLen); err != nil {
+			return nil, err
+		}
+		return hkdf.Key(h, secret, salt, info, keyLen+codeLen) // Derive the key and chain code from a single expand
+	}
+	if err := checkExpand(h, max(keyLen, codeLen)); err != nil {
+		return nil, err // Check the key and chain code, each expanded separately
+	}
+	prk, err := hkdf.Extract(h, secret, salt) // Extract the pseudorandom key from the secret
+	if err != nil {
+		return nil, err
+	}
+	defer clear(prk)                                        // Wipe the pseudorandom key
+	key, err := hkdf.Expand(h, prk, info+"\x00KEY", keyLen) // Expand the key with KEY info
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)                                           // Wipe the key once copied into the ikm
+	code, err := hkdf.Expand(h, prk, info+"\x00CODE", codeLen) // Expand the chain code with CODE info
+	if err != nil {
+		return nil, err
+	}
+	defer clear(code)                    // Wipe the chain code once copied into the ikm
+	return slices.Concat(key, code), nil // Return the key and chain code as ikm
+}
+
+// checkExpand checks if a given length can be expanded with HKDF from a given hash, which RFC 5869
+// limits to 255 blocks of the hash.
+func checkExpand(h func() hash.Hash, length int) error {
+	if limit := 255 * h().Size(); length > limit {
+		return fmt.Errorf(`expand length %d exceeds %d bytes, %w`, length, limit, ErrOutputTooLong)
+	}
+	return nil
+}
+
+// check checks if the key and chain code of a master key have the lengths of derived keys and
+// chain codes, catching malformed keys before they derive children.
+func (p Params) check(master *HDKey) error {
+	keyLen, codeLen, err := p.lengths() // Get the lengths of the key and chain code
+	if err != nil {
+		return err
+	}
+	if len(master.Key) != keyLen || len(master.Code) != codeLen {
+		return fmt.Errorf(`master key and chain code must be %d and %d bytes, got %d and %d`, keyLen, codeLen, len(master.Key), len(master.Code))
+	}
+	return nil
+}
+
+// saltLen returns the length of salts, using 16 bytes when left unset.
+func (p Params) saltLen() int {
+	if p.SaltLen == 0 {
+		return 16
+	}
+	return p.SaltLen
+}
+
+// domain returns the application domain prefixed by its length, or nil without a domain.
+func (p Params) domain() ([]byte, error) {
+	if p.Domain == "" {
+		return nil, nil
+	}
+	if len(p.Domain) > 255 {
+		return nil, fmt.Errorf(`domain cannot exceed 255 bytes, got %d`, len(p.Domain))
+	}
+	return append([]byte{byte(len(p.Domain))}, p.Domain...), nil
+}
+
+// index encodes a child index for HKDF info, as a decimal string by default, or as 4 big endian
+// bytes in binary index mode.
+func (p Params) index(index uint32) string {
+	if !p.BinaryIndex {
+		return strconv.FormatUint(uint64(index), 10) // Encode the index as a decimal string
+	}
+	return string(binary.BigEndian.AppendUint32(nil, index)) // Encode the index as 4 big endian bytes
+}
+
+// info joins components of HKDF info, inserting the separator between components when enabled.
+func (p Params) info(parts ...string) string {
+	if !p.Separate {
+		return strings.Join(parts, "") // Concatenate the components
+	}
+	return strings.Join(parts, string([]byte{p.Separator})) // Join the components with the separator
+}
+
 // Lineage checks if a key is the direct child of a master key, from a given hash, child key, and master key.
 func Lineage(h func() hash.Hash, child, master *HDKey) (bool, error) {
-	fp1 := child.Fingerprint                                // Extract the child fingerprint as fp1
-	fp2, err := utils.Fingerprint(h, master.Key, child.Key) // Derive fp2 from the master and child keys
+	return lineage(h, child, master, nil)
+}
+
+// lineage checks if a key is the direct child of a master key, from a given hash, child key,
+// master key, and associated data folded into the fingerprint.
+func lineage(h func() hash.Hash, child, master *HDKey, aad []byte) (bool, error) {
+	fp1 := child.Fingerprint                                        // Extract the child fingerprint as fp1
+	fp2, err := utils.FingerprintAAD(h, master.Key, child.Key, aad) // Derive fp2 from the master and child keys
 	if err != nil {
 		return false, fmt.Errorf(`lineage fingerprint recalculation, %w`, err)
 	}
-	if len(fp1) != 16 || len(fp2) != 16 {
-		return false, errors.New(`fingerprints for lineage verification must be 16 bytes each`)
+	if len(fp1) != FingerprintLen || len(fp2) != FingerprintLen {
+		return false, fmt.Errorf(`fingerprints for lineage verification must be %d bytes each`, FingerprintLen)
 	}
-	// Complete a constant-time comparison between the 16 bytes of each fingerprint
+	// Complete a constant-time comparison between the bytes of each fingerprint
 	var result byte = 0
-	for i := range 16 {
+	for i := range FingerprintLen {
 		result |= fp1[i] ^ fp2[i]
 	}
 	return result == 0, nil // Return a boolean result of the byte comparison
@@ -2,8 +2,13 @@
 package hdsk_test
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
 	"testing"
 
 	"github.com/jacobhaap/go-hdsk"
@@ -83,6 +88,37 @@ var vectors = []vector{
 	},
 }
 
+// splitVectors are HDSK test vectors for paths parsed with sha256 and keys derived with sha512.
+var splitVectors = []vector{
+	{
+		path: "m/vault/0/1/0",
+		key:  "1420624f266c454258fde26e3c71982e8967d935cb62f1f3d353a1d2cd40aec2",
+	},
+	{
+		path: "m/vault/0/1/1",
+		key:  "72d38e8916b0c62971684abcb19cce8f7e0f0ff480efbd6ca8f341a5f73ca0fa",
+	},
+	{
+		path: "m/vault/notes/1/0",
+		key:  "cfddb41252f0cb254292a86cb56560fce90453b588561199a0445a1a870692d1",
+	},
+	{
+		path: "m/mail/0/1/0",
+		key:  "fab1cea7f8aba58435e9e86367ee398bf1bb0a1879c2c5ca53ac14a63115a62b",
+	},
+}
+
+// testMaster derives the master key used by the test vectors, from a given hash.
+func testMaster(t testing.TB, h func() hash.Hash) hdsk.HDKey {
+	t.Helper()
+	secret := make([]byte, 32)                                    // Create a secret of 32 zero bytes
+	master, err := hdsk.Params{AllowWeak: true}.Master(h, secret) // Derive a master key from the weak secret
+	if err != nil {
+		t.Fatal(err)
+	}
+	return master
+}
+
 // TestHdsk is a test for the hdsk package.
 func TestHdsk(t *testing.T) {
 	h := sha256.New                                // Use sha256 as the hash function
@@ -90,8 +126,8 @@ func TestHdsk(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	secret := make([]byte, 32)            // Create a secret of 32 zero bytes
-	master, err := hdsk.Master(h, secret) // Derive a master key from the hash and secret
+	secret := make([]byte, 32)                                    // Create a secret of 32 zero bytes
+	master, err := hdsk.Params{AllowWeak: true}.Master(h, secret) // Derive a master key from the weak secret
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -121,3 +157,149 @@ func TestHdsk(t *testing.T) {
 		}
 	}
 }
+
+// TestSplitHash is a test for parsing paths and deriving keys with distinct hash functions.
+func TestSplitHash(t *testing.T) {
+	pathHash, kdfHash := sha256.New, sha512.New // Use sha256 for paths and sha512 for keys
+	schema, err := hdsk.Schema("m / application: str / purpose: any / context: any / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := testMaster(t, kdfHash)
+	for _, v := range splitVectors {
+		path, err := hdsk.Path(pathHash, v.path, schema) // Parse the vector derivation path with the path hash
+		if err != nil {
+			t.Fatal(err)
+		}
+		dk, err := hdsk.Node(kdfHash, &master, path) // Derive the node with the key derivation hash
+		if err != nil {
+			t.Fatal(err)
+		}
+		dkHex := hex.EncodeToString(dk.Key)
+		if dkHex != v.key {
+			t.Fatalf(`mismatch for %s: expected %q, got %q`, v.path, v.key, dkHex)
+		}
+	}
+}
+
+// TestWeakSecret is a test for refusing known weak secrets.
+func TestWeakSecret(t *testing.T) {
+	h := sha256.New
+	for _, secret := range [][]byte{nil, make([]byte, 32), bytes.Repeat([]byte{0xFF}, 16)} {
+		if _, err := hdsk.Master(h, secret); !errors.Is(err, hdsk.ErrKnownWeakSecret) {
+			t.Fatalf(`expected ErrKnownWeakSecret for %x, got %v`, secret, err)
+		}
+	}
+	secret := make([]byte, 32)
+	secret[31] = 1
+	if _, err := hdsk.Master(h, secret); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNodeEmptyPath is a test for deriving a node from an empty derivation path.
+func TestNodeEmptyPath(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path(h, "m", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []hdsk.HDPath{path, {}, nil} {
+		node, err := hdsk.Node(h, &master, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(node.Key, master.Key) || node.Depth != master.Depth {
+			t.Fatalf(`expected the master key for an empty path`)
+		}
+	}
+}
+
+// TestDerivePath is a test for parsing and deriving a derivation path in one call.
+func TestDerivePath(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := hdsk.DerivePath(h, &master, vectors[0].path, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(node.Key) != vectors[0].key {
+		t.Fatalf(`expected %s, got %x`, vectors[0].key, node.Key)
+	}
+	if _, err := hdsk.DerivePath(h, &master, "m/42/0/1/0/0", schema); !errors.Is(err, hdsk.ErrTooManyIndices) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrTooManyIndices, err)
+	}
+}
+
+// TestDeriveFromString is a test for deriving a derivation path string and returning the path.
+func TestDeriveFromString(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, node, err := hdsk.DeriveFromString(h, &master, vectors[0].path, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.String() != vectors[0].path || hex.EncodeToString(node.Key) != vectors[0].key {
+		t.Fatalf(`expected %s and %s, got %s and %x`, vectors[0].path, vectors[0].key, path, node.Key)
+	}
+	_, _, err = hdsk.DeriveFromString(h, &master, "m/42/0/1/0/0", schema)
+	if !errors.Is(err, hdsk.ErrTooManyIndices) || !strings.Contains(err.Error(), "parsing") {
+		t.Fatalf(`expected parsing error wrapping %v, got %v`, hdsk.ErrTooManyIndices, err)
+	}
+	malformed := hdsk.HDKey{Key: master.Key, Code: master.Code[:16]}
+	if _, _, err := hdsk.DeriveFromString(h, &malformed, vectors[0].path, schema); err == nil || !strings.Contains(err.Error(), "derivation") {
+		t.Fatalf(`expected derivation error, got %v`, err)
+	}
+}
+
+// TestMaxDepth is a test for refusing to derive keys beyond the maximum depth.
+func TestMaxDepth(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := make(hdsk.HDPath, hdsk.MaxDepth)
+	node, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Depth != hdsk.MaxDepth {
+		t.Fatalf(`expected depth %d, got %d`, hdsk.MaxDepth, node.Depth)
+	}
+	if _, err := hdsk.Child(h, &node, 0); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+	if _, err := hdsk.Node(h, &master, append(path, 0)); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+	wrapped := hdsk.HDKey{Key: master.Key, Code: master.Code, Depth: 0xFFFFFFFF}
+	if _, err := hdsk.Child(h, &wrapped, 0); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v for a depth that would overflow, got %v`, hdsk.ErrMaxDepth, err)
+	}
+}
+
+// TestLengths is a test for the lengths of derived keys, chain codes, and fingerprints.
+func TestLengths(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []hdsk.HDKey{master, child} {
+		if len(key.Key) != hdsk.KeyLen || len(key.Code) != hdsk.CodeLen || len(key.Fingerprint) != hdsk.FingerprintLen {
+			t.Fatalf(`expected lengths %d, %d, and %d, got %d, %d, and %d`, hdsk.KeyLen, hdsk.CodeLen, hdsk.FingerprintLen, len(key.Key), len(key.Code), len(key.Fingerprint))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package hdsk
+
+import (
+	"hash"
+	"time"
+)
+
+// Stats holds instrumentation of a derivation.
+type Stats struct {
+	HashOps  int           // Number of completed hash operations.
+	Duration time.Duration // Duration of the derivation.
+}
+
+// countingHash is a hash that counts completed hash operations.
+type countingHash struct {
+	hash.Hash
+	ops *int // Counter shared by all hashes from the same constructor
+}
+
+// Sum appends the hash digest to b, counting a completed hash operation.
+func (c countingHash) Sum(b []byte) []byte {
+	*c.ops++
+	return c.Hash.Sum(b)
+}
+
+// NodeInstrumented derives a new key at a node in a hierarchy descending from a master key, from a
+// given hash, master key, and derivation path, returning instrumentation of the derivation. The
+// derived key is identical to a key derived with Node.
+func NodeInstrumented(h func() hash.Hash, master *HDKey, path HDPath) (HDKey, Stats, error) {
+	var stats Stats
+	counted := func() hash.Hash {
+		return countingHash{Hash: h(), ops: &stats.HashOps} // Wrap each hash to count its operations
+	}
+	start := time.Now()
+	key, err := Node(counted, master, path) // Derive the node with the counting hash
+	stats.Duration = time.Since(start)
+	if err != nil {
+		return HDKey{}, stats, err
+	}
+	return key, stats, nil // Return the HD key and the instrumentation
+}
@@ -0,0 +1,33 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestNodeInstrumented is a test for instrumented node derivation.
+func TestNodeInstrumented(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1, 0}
+	node, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instrumented, stats, err := hdsk.NodeInstrumented(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(node.Key, instrumented.Key) || !bytes.Equal(node.Fingerprint, instrumented.Fingerprint) {
+		t.Fatalf(`instrumentation must not affect the derived node`)
+	}
+	if stats.HashOps == 0 || stats.HashOps%len(path) != 0 {
+		t.Fatalf(`expected hash operations evenly spread over %d levels, got %d`, len(path), stats.HashOps)
+	}
+	if stats.Duration <= 0 {
+		t.Fatalf(`expected positive duration, got %v`, stats.Duration)
+	}
+}
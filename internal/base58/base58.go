@@ -0,0 +1,104 @@
+// Package base58 provides base58 and base58check encoding with the Bitcoin alphabet.
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// alphabet is the Bitcoin base58 alphabet.
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Encode encodes bytes as a base58 string, with a leading "1" for each leading zero byte.
+func Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++ // Count the leading zero bytes
+	}
+	digits := make([]byte, 0, len(b)*138/100+1) // Base58 digits in little endian order
+	for _, v := range b[zeros:] {
+		carry := int(v)
+		for i := range digits {
+			carry += int(digits[i]) << 8 // Multiply each digit by 256 and add the carry
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros, zeros+len(digits))
+	for i := range out {
+		out[i] = alphabet[0] // Encode each leading zero byte
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		out = append(out, alphabet[digits[i]]) // Encode the digits in big endian order
+	}
+	return string(out)
+}
+
+// Decode decodes bytes from a base58 string.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++ // Count the leading zero digits
+	}
+	b := make([]byte, 0, len(s)*733/1000+1) // Decoded bytes in little endian order
+	for i := zeros; i < len(s); i++ {
+		carry := digit(s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf(`invalid base58 character %q at position %d`, s[i], i)
+		}
+		for j := range b {
+			carry += int(b[j]) * 58 // Multiply each byte by 58 and add the carry
+			b[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			b = append(b, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros, zeros+len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		out = append(out, b[i]) // Decode the bytes in big endian order
+	}
+	return out, nil
+}
+
+// digit returns the value of a base58 character, or -1 for characters outside the alphabet.
+func digit(c byte) int {
+	return bytes.IndexByte([]byte(alphabet), c)
+}
+
+// checksum calculates the 4 byte checksum of a payload, from the double SHA-256 of the payload.
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// CheckEncode encodes a payload as a base58check string, appending a 4 byte checksum.
+func CheckEncode(payload []byte) string {
+	return Encode(append(payload[:len(payload):len(payload)], checksum(payload)...))
+}
+
+// CheckDecode decodes a payload from a base58check string, verifying its checksum.
+func CheckDecode(s string) ([]byte, error) {
+	b, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, errors.New(`base58check string too short for checksum`)
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	if subtle.ConstantTimeCompare(sum, checksum(payload)) != 1 {
+		return nil, errors.New(`invalid base58check checksum`)
+	}
+	return payload, nil
+}
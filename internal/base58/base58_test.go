@@ -0,0 +1,67 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestEncode is a test for base58 encoding.
+func TestEncode(t *testing.T) {
+	for _, c := range []struct {
+		hex, encoded string
+	}{
+		{"", ""},
+		{"00", "1"},
+		{"0000", "11"},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"00000000000000000000", "1111111111"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+	} {
+		b, err := hex.DecodeString(c.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := Encode(b); s != c.encoded {
+			t.Fatalf(`encoding %s: expected %q, got %q`, c.hex, c.encoded, s)
+		}
+		d, err := Decode(c.encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(d, b) {
+			t.Fatalf(`decoding %q: expected %s, got %x`, c.encoded, c.hex, d)
+		}
+	}
+	if _, err := Decode("0OIl"); err == nil {
+		t.Fatalf(`expected error for characters outside the alphabet`)
+	}
+}
+
+// TestCheckEncode is a test for base58check encoding.
+func TestCheckEncode(t *testing.T) {
+	payload, err := hex.DecodeString("00f54a5851e9372b87810a8e60cdd2e7cfd80b6e31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := CheckEncode(payload)
+	if s != "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs" {
+		t.Fatalf(`unexpected base58check string %q`, s)
+	}
+	d, err := CheckDecode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d, payload) {
+		t.Fatalf(`expected %x, got %x`, payload, d)
+	}
+	if _, err := CheckDecode("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAt"); err == nil {
+		t.Fatalf(`expected error for invalid checksum`)
+	}
+	if _, err := CheckDecode("1"); err == nil {
+		t.Fatalf(`expected error for string too short for checksum`)
+	}
+}
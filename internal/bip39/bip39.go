@@ -0,0 +1,69 @@
+// Package bip39 provides validation of BIP39 mnemonics with the English wordlist, and the
+// stretching of mnemonics into seeds.
+package bip39
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed" // Embed the wordlist
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// english is the BIP39 English wordlist, one word per line.
+//
+//go:embed english.txt
+var english string
+
+// indices maps each word of the English wordlist to its index.
+var indices = sync.OnceValue(func() map[string]int {
+	words := strings.Fields(english)
+	m := make(map[string]int, len(words))
+	for i, word := range words {
+		m[word] = i
+	}
+	return m
+})
+
+// ErrChecksum is returned for mnemonics with an invalid checksum.
+var ErrChecksum = errors.New(`invalid mnemonic checksum`)
+
+// Validate checks a mnemonic of words separated by single spaces, requiring 12, 15, 18, 21, or
+// 24 words of the English wordlist and a valid checksum.
+func Validate(mnemonic string) error {
+	words := strings.Split(mnemonic, " ")
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return fmt.Errorf(`mnemonic must have 12, 15, 18, 21, or 24 words, got %d`, len(words))
+	}
+	b := make([]byte, (len(words)*11+7)/8) // Bytes of the concatenated word indices
+	for i, word := range words {
+		index, ok := indices()[word]
+		if !ok {
+			return fmt.Errorf(`unknown word %q at position %d in mnemonic`, word, i)
+		}
+		for bit := range 11 {
+			if index&(1<<(10-bit)) != 0 {
+				pos := i*11 + bit
+				b[pos/8] |= 0x80 >> (pos % 8) // Set each bit of the index in big endian order
+			}
+		}
+	}
+	cs := len(words) / 3                    // Number of checksum bits
+	entropy := b[:(len(words)*11-cs)/8]     // Entropy preceding the checksum bits
+	sum := sha256.Sum256(entropy)           // Hash the entropy for the checksum
+	checksum := b[len(entropy)] >> (8 - cs) // Checksum bits following the entropy
+	if sum[0]>>(8-cs) != checksum {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// Seed stretches a mnemonic and passphrase into a 64 byte seed with PBKDF2-HMAC-SHA512, using
+// 2048 iterations and the passphrase prefixed by "mnemonic" as the salt. The mnemonic and
+// passphrase are used as given, and are not normalized.
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	return pbkdf2.Key(sha512.New, mnemonic, []byte("mnemonic"+passphrase), 2048, 64)
+}
@@ -0,0 +1,67 @@
+package bip39
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestWordlist is a test for the embedded English wordlist.
+func TestWordlist(t *testing.T) {
+	sum := sha256.Sum256([]byte(english))
+	if hex.EncodeToString(sum[:]) != "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda" {
+		t.Fatalf(`unexpected wordlist digest %x`, sum)
+	}
+	if len(indices()) != 2048 {
+		t.Fatalf(`expected 2048 words, got %d`, len(indices()))
+	}
+}
+
+// TestValidate is a test for validating mnemonics.
+func TestValidate(t *testing.T) {
+	for _, mnemonic := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		strings.Repeat("abandon ", 23) + "art",
+		strings.Repeat("zoo ", 23) + "vote",
+	} {
+		if err := Validate(mnemonic); err != nil {
+			t.Fatalf(`mnemonic %q: %v`, mnemonic, err)
+		}
+	}
+	if err := Validate(strings.TrimSpace(strings.Repeat("abandon ", 12))); !errors.Is(err, ErrChecksum) {
+		t.Fatalf(`expected %v, got %v`, ErrChecksum, err)
+	}
+	for _, mnemonic := range []string{
+		"",
+		strings.TrimSpace(strings.Repeat("abandon ", 11)),
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abut",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon  about",
+	} {
+		if err := Validate(mnemonic); err == nil {
+			t.Fatalf(`expected error for mnemonic %q`, mnemonic)
+		}
+	}
+}
+
+// TestSeed is a test for stretching mnemonics into seeds.
+func TestSeed(t *testing.T) {
+	for _, c := range []struct {
+		mnemonic, seed string
+	}{
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"legal winner thank year wave sausage worth useful legal winner thank yellow", "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607"},
+	} {
+		seed, err := Seed(c.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(seed) != c.seed {
+			t.Fatalf(`mnemonic %q: expected %s, got %x`, c.mnemonic, c.seed, seed)
+		}
+	}
+}
@@ -4,92 +4,363 @@ package utils
 import (
 	"crypto/hmac"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // CalcSalt creates a 16 byte salt from a given hash, message, and optional context info.
 func CalcSalt(h func() hash.Hash, msg, info []byte) ([]byte, error) {
+	return CalcSaltDomain(h, msg, info, nil, 16)
+}
+
+// CalcSaltDomain creates a salt of a given length from a given hash, message, optional context
+// info, and optional domain appended to the SALT domain separation bytes. The info is expanded to
+// the length of the salt, which must be at least 16 bytes and cannot exceed the size of the hash.
+// A nil or empty domain and a length of 16 bytes produce the same salt as CalcSalt.
+func CalcSaltDomain(h func() hash.Hash, msg, info, domain []byte, length int) ([]byte, error) {
+	if size := h().Size(); length < 16 || length > size {
+		return nil, fmt.Errorf(`salt length must be between 16 and %d bytes, got %d`, size, length)
+	}
 	if info != nil {
 		hasher := h()
 		_, err := hasher.Write(info) // Hash to expand the info
 		if err != nil {
 			return nil, err
 		}
-		info = hasher.Sum(nil)[:16] // Expanded info from hash digest
+		info = hasher.Sum(nil)[:length] // Expanded info from hash digest
 	} else {
-		info = make([]byte, 16) // 16 byte slice
+		info = make([]byte, length) // Slice of the salt length
 	}
 	mac := hmac.New(h, info) // Create HMAC using info
 	_, err := mac.Write(msg)
 	if err != nil {
 		return nil, err
 	}
-	domain := []byte{83, 65, 76, 84} // Bytes SALT for domain separation
-	_, err = mac.Write(domain)
+	separation := []byte{83, 65, 76, 84} // Bytes SALT for domain separation
+	_, err = mac.Write(append(separation, domain...))
 	if err != nil {
 		return nil, err
 	}
-	return mac.Sum(nil)[:16], nil // Return a salt from the MAC digest
+	return mac.Sum(nil)[:length], nil // Return a salt from the MAC digest
 }
 
-// strToIndex obtains a 32 bit integer from a given hash and string.
-func strToIndex(h func() hash.Hash, str string) (uint32, error) {
+// StrToIndex obtains a 32 bit integer from a given hash and string, as GetIndex does for indices
+// of the plain str type.
+func StrToIndex(h func() hash.Hash, str string) (uint32, error) {
+	value, err := hashToIndex(h, str, 32)
+	return uint32(value), err
+}
+
+// hashToIndex obtains an integer of a given number of bits, 31, 32, 63, or 64, from a given hash and
+// string.
+func hashToIndex(h func() hash.Hash, str string, bits int) (uint64, error) {
 	hasher := h()                       // Create hash
 	_, err := hasher.Write([]byte(str)) // Write string to the hash
 	if err != nil {
 		return 0, err
 	}
 	sum := hasher.Sum(nil)
+	switch bits {
+	case 64:
+		return binary.BigEndian.Uint64(sum[0:8]), nil // Get a 64 bit integer from the hash
+	case 63:
+		return binary.BigEndian.Uint64(sum[0:8]) &^ (1 << 63), nil // Get a 63 bit integer from the hash, leaving the hardened flag unset
+	}
 	value := binary.BigEndian.Uint32(sum[0:4]) // Get a 32 bit integer from the hash
+	if bits == 31 {
+		value &= 0x7FFFFFFF // Limit the integer to 31 bits, leaving the hardened flag unset
+	}
+	return uint64(value), nil
+}
+
+// hexToIndex obtains an integer of a given number of bits, 31, 32, 63, or 64, from a given hash and
+// hexadecimal string. Values of up to four bytes, or eight bytes for 63 and 64 bit integers, are read as
+// big-endian integers, and longer values are hashed.
+func hexToIndex(h func() hash.Hash, str string, bits int) (uint64, error) {
+	b, err := hex.DecodeString(str) // Decode the hexadecimal string
+	if err != nil {
+		return 0, fmt.Errorf(`invalid hexadecimal index %q, %w`, str, err)
+	}
+	if len(b) == 0 {
+		return 0, errors.New(`empty hexadecimal index`)
+	}
+	if len(b) > (bits+1)/8 {
+		return hashToIndex(h, string(b), bits) // Hash values longer than the integer
+	}
+	var buf [8]byte
+	copy(buf[8-len(b):], b)                  // Left pad the value to eight bytes
+	value := binary.BigEndian.Uint64(buf[:]) // Read the value as a big-endian integer
+	if !inRange(value, bits) {
+		return 0, fmt.Errorf(`hexadecimal index %q, %w`, str, rangeError(bits))
+	}
 	return value, nil
 }
 
-// GetIndex obtains a 32 bit integer index from a given hash, index string, and type.
+// types are the permitted base types of schema segments, for strings, numbers, hexadecimal
+// values, or any of strings and numbers.
+var types = map[string]bool{"str": true, "num": true, "hex": true, "any": true}
+
+// fallbacks are the permitted fallback types of the any type, such as "hex" for the type
+// "any!hex", which tries hexadecimal conversion for indices that are not numeric before hashing
+// them as strings, and "str" for the type "any!str", which hashes numeric indices that are not in
+// canonical form, such as "007", as strings.
+var fallbacks = map[string]bool{"hex": true, "str": true}
+
+// splitType splits a type into its base type and arguments, such as "num" and "0..1000" for the
+// type "num(0..1000)".
+func splitType(typ string) (string, string, error) {
+	base, args, ok := strings.Cut(typ, "(")
+	if !ok {
+		return typ, "", nil // Return types without arguments unchanged
+	}
+	args, ok = strings.CutSuffix(args, ")")
+	if !ok || args == "" {
+		return "", "", fmt.Errorf(`malformed arguments in type %q`, typ)
+	}
+	return base, args, nil // Return the base type and arguments
+}
+
+// parseBounds parses inclusive bounds of numeric indices from a given string, such as "0..1000".
+// Bounds are limited to 31 bits, so that a schema bounds 32 and 64 bit indices alike.
+func parseBounds(args string) (uint64, uint64, error) {
+	lo, hi, ok := strings.Cut(args, "..")
+	if !ok {
+		return 0, 0, fmt.Errorf(`malformed bounds %q`, args)
+	}
+	minimum, err := strconv.ParseUint(lo, 10, 31) // Parse the lower bound
+	if err != nil {
+		return 0, 0, fmt.Errorf(`invalid lower bound in %q, %w`, args, err)
+	}
+	maximum, err := strconv.ParseUint(hi, 10, 31) // Parse the upper bound
+	if err != nil {
+		return 0, 0, fmt.Errorf(`invalid upper bound in %q, %w`, args, err)
+	}
+	if minimum > maximum {
+		return 0, 0, fmt.Errorf(`lower bound exceeds upper bound in %q`, args)
+	}
+	return minimum, maximum, nil // Return the bounds
+}
+
+// maxPatterns is the maximum number of compiled patterns of string types held in the cache.
+const maxPatterns = 64
+
+// patterns caches compiled patterns of string types by their source, holding at most maxPatterns
+// patterns, so that schemas built from untrusted input cannot grow the cache without bound.
+var patterns = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+// compilePattern compiles a pattern of string indices from a given string, such as "/^[a-z]+$/",
+// caching the compiled pattern.
+func compilePattern(args string) (*regexp.Regexp, error) {
+	patterns.Lock()
+	defer patterns.Unlock()
+	if re, ok := patterns.m[args]; ok {
+		return re, nil // Return the cached pattern
+	}
+	if len(args) < 2 || args[0] != '/' || args[len(args)-1] != '/' {
+		return nil, fmt.Errorf(`pattern %q must be enclosed in slashes`, args)
+	}
+	re, err := regexp.Compile(args[1 : len(args)-1]) // Compile the pattern between the slashes
+	if err != nil {
+		return nil, fmt.Errorf(`invalid pattern %q, %w`, args, err)
+	}
+	if len(patterns.m) >= maxPatterns {
+		clear(patterns.m) // Empty the full cache before adding the pattern
+	}
+	patterns.m[args] = re // Cache the compiled pattern
+	return re, nil
+}
+
+// BaseType returns the base type of a given type, such as "num" for the type "num(0..1000)", or
+// "any" for the type "any!hex".
+func BaseType(typ string) string {
+	base, _, _ := strings.Cut(typ, "(")
+	base, _, _ = strings.Cut(base, "!")
+	return base
+}
+
+// ValidateType checks if a given type is a permitted schema segment type. Numeric types may have
+// inclusive bounds, such as "num(0..1000)", string types may have a pattern, such as
+// "str(/^[a-z]+$/)", which is compiled once when validated, and the any type may have a fallback
+// type, such as "any!hex".
+func ValidateType(typ string) error {
+	base, args, err := splitType(typ)
+	if err != nil {
+		return err
+	}
+	base, fallback, ok := strings.Cut(base, "!") // Split the fallback type from the base type
+	if !types[base] {
+		return fmt.Errorf(`unknown type %q`, base)
+	}
+	if ok && (base != "any" || !fallbacks[fallback]) {
+		return fmt.Errorf(`type %q does not accept fallback type %q`, base, fallback)
+	}
+	switch {
+	case args == "":
+		return nil
+	case base == "num":
+		_, _, err = parseBounds(args) // Validate the bounds of numeric types
+		return err
+	case base == "str":
+		_, err = compilePattern(args) // Compile the pattern of string types
+		return err
+	default:
+		return fmt.Errorf(`type %q does not accept arguments`, base)
+	}
+}
+
+// ErrIndexOutOfRange is returned for indices outside of the non-hardened range, or outside of
+// the bounds of their type.
+var ErrIndexOutOfRange = errors.New(`index out of range`)
+
+// errNonHardenedRange is returned for numeric indices outside of the non-hardened range.
+var errNonHardenedRange = fmt.Errorf(`parsed index outside of non-hardened range, %w`, ErrIndexOutOfRange)
+
+// errUint32Range is returned for numeric indices outside of the 32 bit range.
+var errUint32Range = fmt.Errorf(`parsed index outside of uint32 range, %w`, ErrIndexOutOfRange)
+
+// inRange checks if a given integer is within the range of a given number of bits.
+func inRange(value uint64, bits int) bool {
+	return bits == 64 || value < 1<<bits
+}
+
+// rangeError returns the error for numeric indices outside of the range of a given number of bits.
+func rangeError(bits int) error {
+	if bits == 32 {
+		return errUint32Range
+	}
+	return errNonHardenedRange
+}
+
+// GetIndex obtains a 32 bit integer index from a given hash, index string, and type. Numeric
+// indices must be within the bounds of their type, string indices must match the pattern of their
+// type, and hexadecimal indices must be valid hexadecimal. Numeric indices of the any type outside
+// of the range are hashed as strings.
+//
+// Only string indices of the plain str type are safe for secret indices, as they are hashed
+// without inspecting their contents. Numeric, hexadecimal, and any indices are parsed with
+// branches on their contents, and patterns of str types are matched against their contents.
 func GetIndex(h func() hash.Hash, index, typ string) (uint32, error) {
-	var i uint32
-	var err error
-	switch typ {
+	value, err := getIndex(h, index, typ, 32)
+	return uint32(value), err
+}
+
+// GetIndex31 obtains a 31 bit integer index from a given hash, index string, and type, like
+// GetIndex, with indices limited to 31 bits and string indices hashed to 31 bit integers, as the
+// highest bit flags hardened indices.
+func GetIndex31(h func() hash.Hash, index, typ string) (uint32, error) {
+	value, err := getIndex(h, index, typ, 31)
+	return uint32(value), err
+}
+
+// GetIndex64 obtains a 64 bit integer index from a given hash, index string, and type, like
+// GetIndex, with string indices hashed to 64 bit integers from the first eight bytes of the hash.
+func GetIndex64(h func() hash.Hash, index, typ string) (uint64, error) {
+	return getIndex(h, index, typ, 64)
+}
+
+// GetIndex63 obtains a 63 bit integer index from a given hash, index string, and type, like
+// GetIndex64, with indices limited to 63 bits and string indices hashed to 63 bit integers, as the
+// highest bit flags hardened indices.
+func GetIndex63(h func() hash.Hash, index, typ string) (uint64, error) {
+	return getIndex(h, index, typ, 63)
+}
+
+// getIndex obtains an integer index of a given number of bits, 31, 32, 63, or 64, from a given hash, index
+// string, and type.
+func getIndex(h func() hash.Hash, index, typ string, bits int) (uint64, error) {
+	base, args, err := splitType(typ) // Split the type into its base type and arguments
+	if err != nil {
+		return 0, err
+	}
+	base, fallback, _ := strings.Cut(base, "!") // Split the fallback type from the base type
+	switch base {
 	case "num":
-		u64, err := strconv.ParseUint(index, 10, 32) // Parse string to integer
-		if u64 > 0xFFFFFFFF {
-			return 0, errors.New(`parsed index outside of uint32 range`)
+		u64, err := strconv.ParseUint(index, 10, min(bits+1, 64)) // Parse string to integer
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf(`invalid numeric index %q, %w, %w`, index, ErrIndexOutOfRange, err)
 		}
-		i = uint32(u64)
 		if err != nil {
 			return 0, fmt.Errorf(`invalid numeric index %q, %w`, index, err)
 		}
+		if !inRange(u64, bits) {
+			return 0, fmt.Errorf(`numeric index %q, %w`, index, rangeError(bits))
+		}
+		if args != "" {
+			minimum, maximum, err := parseBounds(args) // Parse the bounds of the type
+			if err != nil {
+				return 0, err
+			}
+			if u64 < minimum || u64 > maximum {
+				return 0, fmt.Errorf(`numeric index %q outside of range %d..%d, %w`, index, minimum, maximum, ErrIndexOutOfRange)
+			}
+		}
+		return u64, nil // Return the numeric index
 	case "str":
-		i, err = strToIndex(h, index) // Convert string to an integer
+		if args != "" {
+			re, err := compilePattern(args) // Get the pattern of the type
+			if err != nil {
+				return 0, err
+			}
+			if !re.MatchString(index) {
+				return 0, fmt.Errorf(`string index %q does not match pattern %s`, index, args)
+			}
+		}
+		i, err := hashToIndex(h, index, bits) // Convert string to an integer
 		if err != nil {
 			return 0, fmt.Errorf(`invalid alphabetic index %q, %w`, index, err)
 		}
+		return i, nil // Return the string index
+	case "hex":
+		return hexToIndex(h, index, bits) // Return the hexadecimal index
 	case "any":
-		u64, err := strconv.ParseUint(index, 10, 32) // Try parsing integer first
-		if u64 > 0xFFFFFFFF {
-			return 0, errors.New(`parsed index outside of uint32 range`)
+		u64, err := strconv.ParseUint(index, 10, min(bits+1, 64)) // Try parsing integer first, numeric indices outside of the range are strings
+		if fallback == "str" && err == nil && strconv.FormatUint(u64, 10) != index {
+			err = strconv.ErrSyntax // Treat numeric indices not in canonical form as strings
 		}
-		i = uint32(u64)
+		if err == nil && inRange(u64, bits) {
+			return u64, nil // Return the numeric index
+		}
+		if _, err := hex.DecodeString(index); fallback == "hex" && err == nil && index != "" {
+			return hexToIndex(h, index, bits) // Try hexadecimal conversion next, when configured
+		}
+		i, err := hashToIndex(h, index, bits) // Try string conversion last
 		if err != nil {
-			i, err = strToIndex(h, index) // Try string conversion next
-			if err != nil {
-				return 0, fmt.Errorf(`invalid index %q, %w`, index, err)
-			}
+			return 0, fmt.Errorf(`invalid index %q, %w`, index, err)
 		}
+		return i, nil // Return the string index
 	default:
 		return 0, fmt.Errorf(`invalid index type %q`, typ)
 	}
-	return i, nil // Return the index
 }
 
+// FingerprintLen is the length of fingerprints in bytes.
+const FingerprintLen = 16
+
 // Fingerprint calculates a fingerprint from a given hash, parent key, and child key.
 func Fingerprint(h func() hash.Hash, parent, child []byte) ([]byte, error) {
+	return FingerprintAAD(h, parent, child, nil)
+}
+
+// FingerprintAAD calculates a fingerprint from a given hash, parent key, child key, and associated
+// data. A nil or empty associated data produces the same fingerprint as Fingerprint.
+func FingerprintAAD(h func() hash.Hash, parent, child, aad []byte) ([]byte, error) {
 	mac := hmac.New(h, parent) // Create an HMAC using the parent
 	_, err := mac.Write(child) // Write the child to the MAC
 	if err != nil {
 		return nil, err
 	}
-	return mac.Sum(nil)[:16], nil // Return the MAC as the fingerprint
+	_, err = mac.Write(aad) // Write the associated data to the MAC
+	if err != nil {
+		return nil, err
+	}
+	return mac.Sum(nil)[:FingerprintLen], nil // Return the MAC as the fingerprint
 }
@@ -0,0 +1,341 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// TestGetIndex is a test for obtaining indices.
+func TestGetIndex(t *testing.T) {
+	h := sha256.New
+	abc, err := StrToIndex(h, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	neg, err := StrToIndex(h, "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		index, typ string
+		expected   uint32
+	}{
+		{"42", "num", 42},
+		{"2147483647", "num", 0x7FFFFFFF},
+		{"4294967295", "num", 0xFFFFFFFF},
+		{"abc", "str", abc},
+		{"42", "any", 42},
+		{"4294967295", "any", 0xFFFFFFFF},
+		{"4294967296", "any", 1813816320}, // Numeric indices outside of the range are hashed as strings
+		{"abc", "any", abc},
+		{"-1", "any", neg},
+	} {
+		i, err := GetIndex(h, c.index, c.typ)
+		if err != nil {
+			t.Fatalf(`%s index %q: %v`, c.typ, c.index, err)
+		}
+		if i != c.expected {
+			t.Fatalf(`%s index %q: expected %d, got %d`, c.typ, c.index, c.expected, i)
+		}
+	}
+	for _, c := range []struct {
+		index, typ string
+		expected   error
+	}{
+		{"abc", "num", strconv.ErrSyntax},
+		{"-1", "num", strconv.ErrSyntax},
+		{"", "num", strconv.ErrSyntax},
+		{"4294967296", "num", errUint32Range},
+		{"18446744073709551616", "num", strconv.ErrRange},
+	} {
+		_, err := GetIndex(h, c.index, c.typ)
+		if !errors.Is(err, c.expected) {
+			t.Fatalf(`%s index %q: expected %v, got %v`, c.typ, c.index, c.expected, err)
+		}
+	}
+	if _, err := GetIndex(h, "42", "int"); err == nil {
+		t.Fatalf(`expected error for invalid type`)
+	}
+}
+
+// TestGetIndex31 is a test for obtaining 31 bit indices.
+func TestGetIndex31(t *testing.T) {
+	h := sha256.New
+	for _, index := range []string{"abc", "vault", "-1"} {
+		i, err := GetIndex31(h, index, "str")
+		if err != nil {
+			t.Fatal(err)
+		}
+		j, err := GetIndex(h, index, "str")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != j&0x7FFFFFFF {
+			t.Fatalf(`index %q: expected 31 bit string index from the 32 bit string index`, index)
+		}
+	}
+	for _, c := range []struct {
+		index, typ string
+		expected   error
+	}{
+		{"4294967296", "num", strconv.ErrRange},
+		{"2147483648", "num", errNonHardenedRange},
+		{"80000000", "hex", errNonHardenedRange},
+	} {
+		_, err := GetIndex31(h, c.index, c.typ)
+		if !errors.Is(err, c.expected) {
+			t.Fatalf(`%s index %q: expected %v, got %v`, c.typ, c.index, c.expected, err)
+		}
+	}
+	for _, index := range []string{"2147483648", "4294967296"} {
+		i, err := GetIndex31(h, index, "any")
+		if err != nil {
+			t.Fatal(err)
+		}
+		j, err := GetIndex31(h, index, "str")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != j {
+			t.Fatalf(`index %q: expected numeric index outside of the range to be hashed as a string`, index)
+		}
+	}
+}
+
+// TestGetIndexBounds is a test for obtaining indices of bounded numeric types.
+func TestGetIndexBounds(t *testing.T) {
+	h := sha256.New
+	for _, index := range []string{"0", "500", "1000"} {
+		if _, err := GetIndex(h, index, "num(0..1000)"); err != nil {
+			t.Fatalf(`index %q: %v`, index, err)
+		}
+	}
+	for _, index := range []string{"1001", "4294967295"} {
+		if _, err := GetIndex(h, index, "num(0..1000)"); err == nil {
+			t.Fatalf(`expected error for index %q`, index)
+		}
+	}
+	if _, err := GetIndex(h, "9", "num(10..20)"); err == nil {
+		t.Fatalf(`expected error for index below lower bound`)
+	}
+	for _, typ := range []string{"num", "num(0..1000)", "num(7..7)", "str", "any"} {
+		if err := ValidateType(typ); err != nil {
+			t.Fatalf(`type %q: %v`, typ, err)
+		}
+	}
+	for _, typ := range []string{"int", "num(", "num()", "num(1000..0)", "num(0-1000)", "num(0..2147483648)", "any(0..1)"} {
+		if err := ValidateType(typ); err == nil {
+			t.Fatalf(`expected error for type %q`, typ)
+		}
+	}
+}
+
+// TestGetIndexPattern is a test for obtaining indices of string types with a pattern.
+func TestGetIndexPattern(t *testing.T) {
+	h := sha256.New
+	typ := "str(/^[a-z]+$/)"
+	if err := ValidateType(typ); err != nil {
+		t.Fatal(err)
+	}
+	i, err := GetIndex(h, "vault", typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := GetIndex(h, "vault", "str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != j {
+		t.Fatalf(`pattern must not change the index, expected %d, got %d`, j, i)
+	}
+	for _, index := range []string{"Vault", "vault1", ""} {
+		if _, err := GetIndex(h, index, typ); err == nil {
+			t.Fatalf(`expected error for index %q`, index)
+		}
+	}
+	for _, typ := range []string{"str([a-z]+)", "str(/[a-z/)", "str(/)"} {
+		if err := ValidateType(typ); err == nil {
+			t.Fatalf(`expected error for type %q`, typ)
+		}
+	}
+}
+
+// TestGetIndexHex is a test for obtaining indices of hexadecimal types.
+func TestGetIndexHex(t *testing.T) {
+	h := sha256.New
+	long, err := StrToIndex(h, "\xde\xad\xbe\xef\x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abc, err := StrToIndex(h, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	str, err := StrToIndex(h, "2a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		index, typ string
+		expected   uint32
+	}{
+		{"2a", "hex", 42},
+		{"0000002a", "hex", 42},
+		{"1a2B3c4D", "hex", 0x1A2B3C4D},
+		{"7fffffff", "hex", 0x7FFFFFFF},
+		{"deadbeef01", "hex", long},
+		{"42", "any!hex", 42},
+		{"2a", "any!hex", 42},
+		{"abc", "any!hex", abc},
+		{"2a", "any", str},
+	} {
+		i, err := GetIndex(h, c.index, c.typ)
+		if err != nil {
+			t.Fatalf(`%s index %q: %v`, c.typ, c.index, err)
+		}
+		if i != c.expected {
+			t.Fatalf(`%s index %q: expected %d, got %d`, c.typ, c.index, c.expected, i)
+		}
+	}
+	for _, index := range []string{"", "abc", "xyz"} {
+		if _, err := GetIndex(h, index, "hex"); err == nil {
+			t.Fatalf(`expected error for index %q`, index)
+		}
+	}
+	if i, err := GetIndex(h, "deadbeef", "hex"); err != nil || i != 0xDEADBEEF {
+		t.Fatalf(`expected %d, got %d, %v`, uint32(0xDEADBEEF), i, err)
+	}
+	for _, typ := range []string{"hex", "any!hex"} {
+		if err := ValidateType(typ); err != nil {
+			t.Fatalf(`type %q: %v`, typ, err)
+		}
+	}
+	for _, typ := range []string{"num!hex", "str!hex", "any!num", "any!", "hex(0..1)"} {
+		if err := ValidateType(typ); err == nil {
+			t.Fatalf(`expected error for type %q`, typ)
+		}
+	}
+}
+
+// TestGetIndex64 is a test for obtaining 64 bit indices.
+func TestGetIndex64(t *testing.T) {
+	h := sha256.New
+	for _, c := range []struct {
+		index, typ string
+		expected   uint64
+	}{
+		{"42", "num", 42},
+		{"9223372036854775808", "num", 1 << 63},
+		{"18446744073709551615", "num", 1<<64 - 1},
+		{"1099511627776", "any", 1 << 40},
+		{"18446744073709551615", "any", 1<<64 - 1},
+		{"0102030405060708", "hex", 0x0102030405060708},
+		{"ffffffffffffffff", "hex", 1<<64 - 1},
+	} {
+		i, err := GetIndex64(h, c.index, c.typ)
+		if err != nil {
+			t.Fatalf(`%s index %q: %v`, c.typ, c.index, err)
+		}
+		if i != c.expected {
+			t.Fatalf(`%s index %q: expected %d, got %d`, c.typ, c.index, c.expected, i)
+		}
+	}
+	i, err := GetIndex64(h, "abc", "str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := GetIndex(h, "abc", "str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i>>32 != uint64(j) {
+		t.Fatalf(`expected 64 bit string index from the first eight bytes of the hash`)
+	}
+	if _, err := GetIndex64(h, "18446744073709551616", "num"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf(`expected %v, got %v`, ErrIndexOutOfRange, err)
+	}
+}
+
+// TestGetIndex63 is a test for obtaining 63 bit indices.
+func TestGetIndex63(t *testing.T) {
+	h := sha256.New
+	i, err := GetIndex63(h, "9223372036854775807", "num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 1<<63-1 {
+		t.Fatalf(`expected %d, got %d`, uint64(1<<63-1), i)
+	}
+	j, err := GetIndex63(h, "abc", "str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := GetIndex64(h, "abc", "str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j != k&^(1<<63) {
+		t.Fatalf(`expected 63 bit string index without the highest bit`)
+	}
+	for _, index := range []string{"9223372036854775808", "18446744073709551616"} {
+		if _, err := GetIndex63(h, index, "num"); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Fatalf(`index %q: expected %v, got %v`, index, ErrIndexOutOfRange, err)
+		}
+	}
+}
+
+// TestGetIndexPreferString is a test for obtaining indices of any types preferring strings.
+func TestGetIndexPreferString(t *testing.T) {
+	h := sha256.New
+	if err := ValidateType("any!str"); err != nil {
+		t.Fatal(err)
+	}
+	padded, err := StrToIndex(h, "007")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abc, err := StrToIndex(h, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		index, typ string
+		expected   uint32
+	}{
+		{"007", "any", 7},
+		{"42", "any", 42},
+		{"abc", "any", abc},
+		{"007", "any!str", padded},
+		{"42", "any!str", 42},
+		{"abc", "any!str", abc},
+	} {
+		i, err := GetIndex(h, c.index, c.typ)
+		if err != nil {
+			t.Fatalf(`%s index %q: %v`, c.typ, c.index, err)
+		}
+		if i != c.expected {
+			t.Fatalf(`%s index %q: expected %d, got %d`, c.typ, c.index, c.expected, i)
+		}
+	}
+}
+
+// TestPatternCacheBounded is a test for bounding the cache of compiled patterns.
+func TestPatternCacheBounded(t *testing.T) {
+	for i := range 2 * maxPatterns {
+		re, err := compilePattern("/^" + strconv.Itoa(i) + "$/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !re.MatchString(strconv.Itoa(i)) {
+			t.Fatalf("pattern %d did not match its index", i)
+		}
+	}
+	patterns.Lock()
+	n := len(patterns.m)
+	patterns.Unlock()
+	if n > maxPatterns {
+		t.Fatalf("cache holds %d patterns, want at most %d", n, maxPatterns)
+	}
+}
@@ -0,0 +1,248 @@
+package hdsk
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// expand derives key material bound to a key, from a given hash, info, and length. Lengths
+// beyond 255 blocks of the hash are refused with ErrOutputTooLong.
+func (k *HDKey) expand(h func() hash.Hash, info string, length int) ([]byte, error) {
+	if err := checkExpand(h, length); err != nil {
+		return nil, err
+	}
+	salt, err := utils.CalcSalt(h, k.Key, nil) // Derive salt from the key
+	if err != nil {
+		return nil, fmt.Errorf(`salt, %w`, err)
+	}
+	out, err := hkdf.Key(h, k.Key, salt, info, length) // Derive key material from the key
+	if err != nil {
+		return nil, fmt.Errorf(`hkdf, %w`, err)
+	}
+	return out, nil // Return the key material
+}
+
+// SubKey derives a subkey of a given length from a given hash, label, and length. Distinct labels
+// produce independent subkeys, and the same label always produces the same subkey.
+func (k *HDKey) SubKey(h func() hash.Hash, label []byte, length int) ([]byte, error) {
+	return Params{}.SubKey(h, k, label, length)
+}
+
+// SubKey derives a subkey of a given length from a given hash, key, label, and length, like
+// HDKey.SubKey, inserting the separator of the parameters between SUBKEY and the label.
+func (p Params) SubKey(h func() hash.Hash, k *HDKey, label []byte, length int) ([]byte, error) {
+	sub, err := k.expand(h, p.info("SUBKEY", string(label)), length) // Derive the subkey with SUBKEY + label as info
+	if err != nil {
+		return nil, fmt.Errorf(`subkey %w`, err)
+	}
+	return sub, nil // Return the subkey
+}
+
+// SubKey32 derives a 32 byte subkey as an array from a given hash and label, producing the same
+// bytes as SubKey with a length of 32. The returned array can stay off the heap in the caller,
+// although the derivation itself allocates as much as SubKey does.
+func (k *HDKey) SubKey32(h func() hash.Hash, label []byte) ([32]byte, error) {
+	var sub [32]byte
+	b, err := k.SubKey(h, label, 32) // Derive the subkey
+	if err != nil {
+		return sub, err
+	}
+	copy(sub[:], b)
+	clear(b)        // Wipe the intermediate subkey slice
+	return sub, nil // Return the subkey array
+}
+
+// Finalize derives a 32 byte leaf key from a given hash and label. Leaf keys carry no chain
+// code, marking the end of a hierarchy as no further keys can be derived from them.
+func (k *HDKey) Finalize(h func() hash.Hash, label []byte) ([]byte, error) {
+	return Params{}.Finalize(h, k, label)
+}
+
+// Finalize derives a 32 byte leaf key from a given hash, key, and label, like HDKey.Finalize,
+// inserting the separator of the parameters between LEAF and the label.
+func (p Params) Finalize(h func() hash.Hash, k *HDKey, label []byte) ([]byte, error) {
+	leaf, err := k.expand(h, p.info("LEAF", string(label)), 32) // Derive the leaf key with LEAF + label as info
+	if err != nil {
+		return nil, fmt.Errorf(`leaf key %w`, err)
+	}
+	return leaf, nil // Return the leaf key
+}
+
+// IV derives a deterministic initialization vector of a given size, from a given hash, counter,
+// and size. Distinct counters produce distinct initialization vectors for the same key, and a
+// counter must never be reused with the same key, as doing so repeats the initialization vector.
+func (k *HDKey) IV(h func() hash.Hash, counter uint64, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf(`initialization vector size must be positive, got %d`, size)
+	}
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, counter)       // Context info from bytes of encoded counter
+	iv, err := k.expand(h, "IV"+string(info), size) // Derive the initialization vector with IV + counter as info
+	if err != nil {
+		return nil, fmt.Errorf(`initialization vector %w`, err)
+	}
+	return iv, nil // Return the initialization vector
+}
+
+// commit calculates a commitment to a key from a given hash and key.
+func commit(h func() hash.Hash, key []byte) ([]byte, error) {
+	hasher := h()
+	_, err := hasher.Write([]byte("COMMIT")) // Domain separation for commitments
+	if err != nil {
+		return nil, err
+	}
+	_, err = hasher.Write(key) // Write the key to the hash
+	if err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil // Return the hash digest as the commitment
+}
+
+// Commit derives a commitment to the 32 byte subkey for a label, from a given hash and label. The
+// commitment binds to the subkey while hiding it, and is verified once the subkey is revealed.
+func (k *HDKey) Commit(h func() hash.Hash, label []byte) ([]byte, error) {
+	sub, err := k.SubKey(h, label, 32) // Derive the subkey being committed to
+	if err != nil {
+		return nil, fmt.Errorf(`commitment %w`, err)
+	}
+	defer clear(sub)
+	commitment, err := commit(h, sub) // Calculate the commitment to the subkey
+	if err != nil {
+		return nil, fmt.Errorf(`commitment hash, %w`, err)
+	}
+	return commitment, nil // Return the commitment
+}
+
+// VerifyCommitment checks if a revealed key matches a commitment, from a given hash, key, and
+// commitment.
+func VerifyCommitment(h func() hash.Hash, key, commitment []byte) bool {
+	expected, err := commit(h, key) // Recalculate the commitment from the revealed key
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(expected, commitment) == 1 // Compare the commitments in constant time
+}
+
+// VersionedKey derives a key of a given length for a version, from a given hash, version, and
+// length. Every version is derived independently from the key, so the key for any version can be
+// derived at any time. This offers no forward secrecy, as all versions are derivable from the key.
+func (k *HDKey) VersionedKey(h func() hash.Hash, version uint32, length int) ([]byte, error) {
+	return Params{}.VersionedKey(h, k, version, length)
+}
+
+// VersionedKey derives a key of a given length for a version, from a given hash, key, version,
+// and length, like HDKey.VersionedKey, inserting the separator of the parameters between VERSION
+// and the version.
+func (p Params) VersionedKey(h func() hash.Hash, k *HDKey, version uint32, length int) ([]byte, error) {
+	info := make([]byte, 4)
+	binary.BigEndian.PutUint32(info, version)                        // Context info from bytes of encoded version
+	key, err := k.expand(h, p.info("VERSION", string(info)), length) // Derive the key with VERSION + version as info
+	if err != nil {
+		return nil, fmt.Errorf(`versioned key %w`, err)
+	}
+	return key, nil // Return the versioned key
+}
+
+// JWTKey derives a 32 byte HMAC key for signing HS256 JSON Web Tokens, from a given hash and
+// tenant. Distinct tenants produce independent signing keys, and the same tenant always produces
+// the same signing key, such as for use as the key of jwt.SigningMethodHS256 from
+// github.com/golang-jwt/jwt.
+func (k *HDKey) JWTKey(h func() hash.Hash, tenant []byte) ([]byte, error) {
+	return Params{}.JWTKey(h, k, tenant)
+}
+
+// JWTKey derives a 32 byte HMAC key for signing HS256 JSON Web Tokens, from a given hash, key, and
+// tenant, like HDKey.JWTKey, inserting the separator of the parameters between JWT and the tenant.
+func (p Params) JWTKey(h func() hash.Hash, k *HDKey, tenant []byte) ([]byte, error) {
+	key, err := k.expand(h, p.info("JWT", string(tenant)), 32) // Derive the signing key with JWT + tenant as info
+	if err != nil {
+		return nil, fmt.Errorf(`jwt key %w`, err)
+	}
+	return key, nil // Return the signing key
+}
+
+// KDFDerive derives a subkey of a given length from a given subkey id, context, and length,
+// mirroring the interface of crypto_kdf_derive_from_key in libsodium. Subkeys are derived with
+// HKDF using sha512 rather than the BLAKE2b construction of libsodium, so they are not byte
+// compatible with libsodium. As with libsodium, the length must be between 16 and 64 bytes.
+func (k *HDKey) KDFDerive(subkeyID uint64, context [8]byte, length int) ([]byte, error) {
+	if length < 16 || length > 64 {
+		return nil, fmt.Errorf(`kdf subkey length must be between 16 and 64 bytes, got %d`, length)
+	}
+	info := make([]byte, 16)
+	copy(info, context[:])                                       // Context info from the context
+	binary.LittleEndian.PutUint64(info[8:], subkeyID)            // Followed by the little endian subkey id, as in libsodium
+	sub, err := k.expand(sha512.New, "KDF"+string(info), length) // Derive the subkey with KDF + context + id as info
+	if err != nil {
+		return nil, fmt.Errorf(`kdf subkey %w`, err)
+	}
+	return sub, nil // Return the subkey
+}
+
+// Checksum calculates a keyed checksum of a given size over data, from a given hash, data, and
+// size. The checksum is an HMAC truncated to the size, using a key derived for checksums, and
+// sizes below 16 bytes weaken the integrity it provides.
+func (k *HDKey) Checksum(h func() hash.Hash, data []byte, size int) ([]byte, error) {
+	mac := h()
+	if size <= 0 || size > mac.Size() {
+		return nil, fmt.Errorf(`checksum size must be between 1 and %d bytes, got %d`, mac.Size(), size)
+	}
+	key, err := k.expand(h, "CHECKSUM", 32) // Derive the MAC key with CHECKSUM as info
+	if err != nil {
+		return nil, fmt.Errorf(`checksum key %w`, err)
+	}
+	defer clear(key)
+	mac = hmac.New(h, key) // Create an HMAC using the MAC key
+	_, err = mac.Write(data)
+	if err != nil {
+		return nil, fmt.Errorf(`checksum, %w`, err)
+	}
+	return mac.Sum(nil)[:size], nil // Return the truncated MAC as the checksum
+}
+
+// TimeBoundKey derives a key of a given length bound to an expiry, from a given hash, expiry as
+// a unix timestamp, and length. The expiry is mixed into the derivation rather than stored as
+// metadata, so a verifier re-deriving the key with a different expiry obtains a different key.
+func (k *HDKey) TimeBoundKey(h func() hash.Hash, notAfter int64, length int) ([]byte, error) {
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, uint64(notAfter))        // Context info from bytes of encoded expiry
+	key, err := k.expand(h, "TIMEBOUND"+string(info), length) // Derive the key with TIMEBOUND + expiry as info
+	if err != nil {
+		return nil, fmt.Errorf(`time bound key %w`, err)
+	}
+	return key, nil // Return the time bound key
+}
+
+// Authenticate calculates a tag authenticating a message, from a given hash and message. The tag
+// is an HMAC over the message, using a key derived for authentication with AUTH as info, so the
+// key itself is never used as a MAC key. The MAC key is a single block of the hash, within the
+// limit of HKDF, so the tag is only nil for a hash that fails to write.
+func (k *HDKey) Authenticate(h func() hash.Hash, msg []byte) []byte {
+	key, err := k.expand(h, "AUTH", h().Size()) // Derive the MAC key with AUTH as info
+	if err != nil {
+		return nil
+	}
+	defer clear(key)
+	mac := hmac.New(h, key) // Create an HMAC using the MAC key
+	if _, err := mac.Write(msg); err != nil {
+		return nil
+	}
+	return mac.Sum(nil) // Return the MAC as the tag
+}
+
+// Verify checks if a tag authenticates a message, from a given hash, message, and tag, comparing
+// the tag in constant time.
+func (k *HDKey) Verify(h func() hash.Hash, msg, tag []byte) bool {
+	expected := k.Authenticate(h, msg) // Recalculate the tag for the message
+	if expected == nil {
+		return false // Reject every tag when the tag cannot be calculated
+	}
+	return subtle.ConstantTimeCompare(expected, tag) == 1 // Compare the tags in constant time
+}
@@ -0,0 +1,401 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestFinalize is a test for deriving leaf keys.
+func TestFinalize(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	node, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf1, err := node.Finalize(h, []byte("file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf2, err := node.Finalize(h, []byte("file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaf1) != 32 || !bytes.Equal(leaf1, leaf2) {
+		t.Fatalf(`leaf keys are not deterministic 32 byte keys`)
+	}
+	leaf3, err := node.Finalize(h, []byte("note"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(leaf1, leaf3) {
+		t.Fatalf(`leaf keys for distinct labels must differ`)
+	}
+	if bytes.Equal(leaf1, node.Key) {
+		t.Fatalf(`leaf key must differ from the node key`)
+	}
+}
+
+// TestIV is a test for deriving initialization vectors.
+func TestIV(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	seen := make(map[string]bool)
+	for counter := range uint64(64) {
+		iv, err := master.IV(h, counter, 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(iv) != 12 {
+			t.Fatalf(`expected 12 byte initialization vector, got %d`, len(iv))
+		}
+		if seen[string(iv)] {
+			t.Fatalf(`repeated initialization vector for counter %d`, counter)
+		}
+		seen[string(iv)] = true
+	}
+	iv1, err := master.IV(h, 7, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv2, err := master.IV(h, 7, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(iv1, iv2) {
+		t.Fatalf(`initialization vectors are not deterministic`)
+	}
+	if _, err := master.IV(h, 0, 0); err == nil {
+		t.Fatalf(`expected error for zero size`)
+	}
+}
+
+// TestSubKey is a test for deriving subkeys.
+func TestSubKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	enc, err := master.SubKey(h, []byte("encryption"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := master.SubKey(h, []byte("authentication"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(enc, auth) {
+		t.Fatalf(`subkeys for distinct labels must differ`)
+	}
+	leaf, err := master.Finalize(h, []byte("encryption"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(enc, leaf) {
+		t.Fatalf(`subkey and leaf key for the same label must differ`)
+	}
+}
+
+// TestSubKeyVectors is a test for deriving subkeys matching known vectors.
+func TestSubKeyVectors(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	for _, v := range []struct {
+		label    string
+		length   int
+		expected string
+	}{
+		{"encryption", 32, "686d38a48d6692d9cc96dcdaff3c5e894106f99bc519ede2608fcb4a6891fee6"},
+		{"encryption", 64, "686d38a48d6692d9cc96dcdaff3c5e894106f99bc519ede2608fcb4a6891fee6e230d70b7573b1f4832a63b502c0a0b2c869580e54e72f4e79393e8496def5da"},
+		{"authentication", 32, "da151420e5f368fa5e235e632f6f667c08ca11115996262b725732b9ef76b569"},
+		{"authentication", 64, "da151420e5f368fa5e235e632f6f667c08ca11115996262b725732b9ef76b5696f6d51a93e9e53cd788c6b33f5159c9a8ee04043022d19290702adc6cd7c567d"},
+	} {
+		sub, err := master.SubKey(h, []byte(v.label), v.length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(sub) != v.expected {
+			t.Fatalf(`subkey for label %q of length %d: expected %s, got %x`, v.label, v.length, v.expected, sub)
+		}
+	}
+}
+
+// TestSubKeyLimit is a test for deriving subkeys at the HKDF output length limit.
+func TestSubKeyLimit(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	limit := 255 * sha256.Size // Longest output with a single byte block counter
+	sub, err := master.SubKey(h, []byte("limit"), limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sub) != limit {
+		t.Fatalf(`expected %d byte subkey, got %d`, limit, len(sub))
+	}
+	if _, err := master.SubKey(h, []byte("limit"), limit+1); !errors.Is(err, hdsk.ErrOutputTooLong) {
+		t.Fatalf(`expected %v for subkey beyond the block counter range, got %v`, hdsk.ErrOutputTooLong, err)
+	}
+	if _, err := master.TimeBoundKey(h, 0, limit+1); !errors.Is(err, hdsk.ErrOutputTooLong) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrOutputTooLong, err)
+	}
+}
+
+// TestCommit is a test for key commitments.
+func TestCommit(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	label := []byte("auction")
+	commitment, err := master.Commit(h, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := master.SubKey(h, label, 32) // Reveal the committed subkey
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(commitment, key) {
+		t.Fatalf(`commitment must not contain the key`)
+	}
+	if !hdsk.VerifyCommitment(h, key, commitment) {
+		t.Fatalf(`revealed key does not match commitment`)
+	}
+	tampered := bytes.Clone(key)
+	tampered[0] ^= 1 // Flip a bit of the revealed key
+	if hdsk.VerifyCommitment(h, tampered, commitment) {
+		t.Fatalf(`tampered key must not match commitment`)
+	}
+	other, err := master.SubKey(h, []byte("other"), 32) // Reveal a subkey for a different label
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdsk.VerifyCommitment(h, other, commitment) {
+		t.Fatalf(`key for another label must not match commitment`)
+	}
+	commitment[len(commitment)-1] ^= 1 // Flip a bit of the commitment
+	if hdsk.VerifyCommitment(h, key, commitment) {
+		t.Fatalf(`key must not match tampered commitment`)
+	}
+}
+
+// TestVersionedKey is a test for deriving versioned keys.
+func TestVersionedKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	keys := make([][]byte, 0, 3)
+	for version := range uint32(3) {
+		key, err := master.VersionedKey(h, version, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range keys {
+			if bytes.Equal(keys[i], key) {
+				t.Fatalf(`keys for versions %d and %d must differ`, i, version)
+			}
+		}
+		keys = append(keys, key)
+	}
+	old, err := master.VersionedKey(h, 1, 32) // Derive an older version on demand
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old, keys[1]) {
+		t.Fatalf(`versioned keys are not deterministic`)
+	}
+}
+
+// TestJWTKey is a test for deriving JSON Web Token signing keys.
+func TestJWTKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	key1, err := master.JWTKey(h, []byte("tenant-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := master.JWTKey(h, []byte("tenant-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key1) != 32 || !bytes.Equal(key1, key2) {
+		t.Fatalf(`signing keys are not deterministic 32 byte keys`)
+	}
+	key3, err := master.JWTKey(h, []byte("tenant-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key1, key3) {
+		t.Fatalf(`signing keys for distinct tenants must differ`)
+	}
+}
+
+// TestKDFDerive is a test for deriving subkeys with the libsodium kdf interface.
+func TestKDFDerive(t *testing.T) {
+	master := testMaster(t, sha256.New)
+	context := [8]byte{'E', 'x', 'a', 'm', 'p', 'l', 'e', 's'}
+	sub1, err := master.KDFDerive(1, context, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub2, err := master.KDFDerive(1, context, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sub1, sub2) {
+		t.Fatalf(`kdf subkeys are not deterministic`)
+	}
+	sub3, err := master.KDFDerive(2, context, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := [8]byte{'O', 't', 'h', 'e', 'r', 0, 0, 0}
+	sub4, err := master.KDFDerive(1, other, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sub1, sub3) || bytes.Equal(sub1, sub4) {
+		t.Fatalf(`kdf subkeys for distinct ids or contexts must differ`)
+	}
+	for _, length := range []int{15, 65} {
+		if _, err := master.KDFDerive(1, context, length); err == nil {
+			t.Fatalf(`expected error for length %d`, length)
+		}
+	}
+}
+
+// TestChecksum is a test for keyed checksums.
+func TestChecksum(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	blob := []byte("stored blob contents")
+	sum, err := master.Checksum(h, blob, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum) != 16 {
+		t.Fatalf(`expected 16 byte checksum, got %d`, len(sum))
+	}
+	tampered := bytes.Clone(blob)
+	tampered[0] ^= 1 // Flip a bit of the blob
+	tamperedSum, err := master.Checksum(h, tampered, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sum, tamperedSum) {
+		t.Fatalf(`checksum must detect tampered data`)
+	}
+	full, err := master.Checksum(h, blob, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sum, full[:16]) {
+		t.Fatalf(`truncated checksum must be a prefix of the full checksum`)
+	}
+	for _, size := range []int{0, 33} {
+		if _, err := master.Checksum(h, blob, size); err == nil {
+			t.Fatalf(`expected error for size %d`, size)
+		}
+	}
+}
+
+// TestSubKey32 is a test for deriving subkeys as arrays.
+func TestSubKey32(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	sub, err := master.SubKey(h, []byte("encryption"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr, err := master.SubKey32(h, []byte("encryption"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sub, arr[:]) {
+		t.Fatalf(`array subkey must match slice subkey`)
+	}
+}
+
+// BenchmarkSubKey is a benchmark for deriving subkeys as slices.
+func BenchmarkSubKey(b *testing.B) {
+	h := sha256.New
+	master := testMaster(b, h)
+	label := []byte("encryption")
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := master.SubKey(h, label, 32); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSubKey32 is a benchmark for deriving subkeys as arrays.
+func BenchmarkSubKey32(b *testing.B) {
+	h := sha256.New
+	master := testMaster(b, h)
+	label := []byte("encryption")
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := master.SubKey32(h, label); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestTimeBoundKey is a test for deriving keys bound to an expiry.
+func TestTimeBoundKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	expiry := int64(1767225600) // 2026-01-01 00:00:00 UTC
+	key1, err := master.TimeBoundKey(h, expiry, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := master.TimeBoundKey(h, expiry, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key1, key2) {
+		t.Fatalf(`time bound keys are not deterministic`)
+	}
+	wrong, err := master.TimeBoundKey(h, expiry+1, 32) // Re-derive with a tampered expiry
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key1, wrong) {
+		t.Fatalf(`time bound keys for distinct expiries must differ`)
+	}
+}
+
+// TestAuthenticate is a test for authenticating messages.
+func TestAuthenticate(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	msg := []byte("message")
+	tag := master.Authenticate(h, msg)
+	if len(tag) != sha256.Size || !master.Verify(h, msg, tag) {
+		t.Fatalf(`expected a valid tag of %d bytes`, sha256.Size)
+	}
+	if master.Verify(h, []byte("messages"), tag) {
+		t.Fatalf(`expected tag to fail for a different message`)
+	}
+	tampered := bytes.Clone(tag)
+	tampered[0] ^= 0x01
+	if master.Verify(h, msg, tampered) || master.Verify(h, msg, tag[:16]) || master.Verify(h, msg, nil) {
+		t.Fatalf(`expected tampered and truncated tags to fail`)
+	}
+	checksum, err := master.Checksum(h, msg, sha256.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(tag, checksum) {
+		t.Fatalf(`expected tags and checksums to use distinct keys`)
+	}
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Verify(h, msg, tag) {
+		t.Fatalf(`expected tag to fail under a different key`)
+	}
+}
@@ -0,0 +1,32 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// DeriveLabel derives the child key of a key for the segment of a derivation path schema with a
+// given label, from a given hash, schema, label, and value, parsing the value as an index of the
+// segment's type like Path. The depth of the key must match the position of the label in the
+// schema, or be at or beyond the position of a wildcard label.
+func (k *HDKey) DeriveLabel(h func() hash.Hash, s HDSchema, label, value string) (HDKey, error) {
+	pos, ok := s.IndexOf(label) // Find the position of the label in the schema
+	if !ok {
+		return HDKey{}, fmt.Errorf(`label %q not found in schema`, label)
+	}
+	depth := int(k.Depth) // Depth of the key as a position in the schema
+	if depth != pos && (!s.wildcard() || pos != len(s)-1 || depth < pos) {
+		return HDKey{}, fmt.Errorf(`key depth %d does not match position %d of label %q`, k.Depth, pos, label)
+	}
+	if value == "" {
+		return HDKey{}, fmt.Errorf(`empty index for label %q, %w`, label, ErrEmptyIndex)
+	}
+	_, typ := s.segment(pos)                                   // Get the type of the segment for the label
+	index, err := parseIndex(h, value, typ, utils.GetIndex, 0) // Parse the value, enforcing the type from the schema
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`label %q, %w`, label, err)
+	}
+	return Child(h, k, index) // Derive the child key for the index
+}
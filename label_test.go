@@ -0,0 +1,70 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestDeriveLabel is a test for deriving child keys for labeled schema segments.
+func TestDeriveLabel(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	schema, err := hdsk.Schema("m / application: str / purpose: num / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path(h, "m/wallet/7/0", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := master
+	for _, label := range [][2]string{{"application", "wallet"}, {"purpose", "7"}, {"index", "0"}} {
+		key, err = key.DeriveLabel(h, schema, label[0], label[1]) // Derive the child key for the label
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !key.Equal(&expected) {
+		t.Fatalf(`expected the key of the derivation path`)
+	}
+	if _, err := master.DeriveLabel(h, schema, "missing", "0"); err == nil {
+		t.Fatalf(`expected error for a missing label`)
+	}
+	if _, err := master.DeriveLabel(h, schema, "purpose", "7"); err == nil {
+		t.Fatalf(`expected error for a key depth not matching the label`)
+	}
+	child, err := master.DeriveLabel(h, schema, "application", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.DeriveLabel(h, schema, "purpose", "7'"); err == nil {
+		t.Fatalf(`expected error for a hardened marker outside of hardened index mode`)
+	}
+	if _, err := child.DeriveLabel(h, schema, "purpose", "x"); err == nil {
+		t.Fatalf(`expected error for an index not matching the type`)
+	}
+	if _, err := child.DeriveLabel(h, schema, "purpose", ""); !errors.Is(err, hdsk.ErrEmptyIndex) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrEmptyIndex, err)
+	}
+	wildcard, err := hdsk.Schema("m / application: str / tail: num*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tail, err := child.DeriveLabel(h, wildcard, "tail", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tail.DeriveLabel(h, wildcard, "tail", "2"); err != nil {
+		t.Fatalf(`expected a wildcard label beyond its position, got %v`, err)
+	}
+	if _, err := master.DeriveLabel(h, wildcard, "tail", "1"); err == nil {
+		t.Fatalf(`expected error for a key before the wildcard label`)
+	}
+}
@@ -0,0 +1,80 @@
+package hdsk
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// FindParent finds the direct parent of a key among candidate keys, from a given hash, child key,
+// and candidate keys. Every candidate is checked in constant time, returning the index of the
+// matching candidate and whether a match was found.
+func FindParent(h func() hash.Hash, child *HDKey, candidates []HDKey) (int, bool, error) {
+	if len(child.Fingerprint) != FingerprintLen {
+		return -1, false, fmt.Errorf(`fingerprints for lineage verification must be %d bytes each`, FingerprintLen)
+	}
+	index := -1 // Index of the matching candidate, -1 when none match
+	for i := range candidates {
+		fp, err := utils.Fingerprint(h, candidates[i].Key, child.Key) // Derive a fingerprint from the candidate and child keys
+		if err != nil {
+			return -1, false, fmt.Errorf(`parent candidate %d fingerprint recalculation, %w`, i, err)
+		}
+		// Select the candidate index without branching on the result of the comparison
+		match := subtle.ConstantTimeCompare(child.Fingerprint, fp)
+		index = subtle.ConstantTimeSelect(match, i, index)
+	}
+	return index, index >= 0, nil // Return the index of the parent and the match result
+}
+
+// VerifyChildren checks if each of a set of keys is a direct child of a parent key, from a given
+// hash, parent key, and child keys, returning the lineage result for each child.
+func VerifyChildren(h func() hash.Hash, parent *HDKey, children []HDKey) ([]bool, error) {
+	mac := hmac.New(h, parent.Key)         // Create a single HMAC using the parent for all children
+	results := make([]bool, len(children)) // Allocate slice for the lineage results
+	for i := range children {
+		if len(children[i].Fingerprint) != FingerprintLen {
+			return nil, fmt.Errorf(`child %d, fingerprints for lineage verification must be %d bytes each`, i, FingerprintLen)
+		}
+		mac.Reset()
+		_, err := mac.Write(children[i].Key) // Write the child to the MAC
+		if err != nil {
+			return nil, fmt.Errorf(`child %d lineage fingerprint recalculation, %w`, i, err)
+		}
+		fp := mac.Sum(nil)[:FingerprintLen]                                       // Recalculated fingerprint from the MAC digest
+		results[i] = subtle.ConstantTimeCompare(children[i].Fingerprint, fp) == 1 // Compare the fingerprints in constant time
+	}
+	return results, nil // Return the lineage results
+}
+
+// Ancestry checks if a key descends from a master key along a derivation path, from a given hash,
+// node key, master key, and derivation path. The node is derived again from the master key, and
+// its key and fingerprint are compared in constant time, so a mismatch is reported as false and
+// only failures to derive the node are returned as errors.
+func Ancestry(h func() hash.Hash, node *HDKey, master *HDKey, path HDPath) (bool, error) {
+	derived, err := Node(h, master, path) // Derive the node again from the master key
+	if err != nil {
+		return false, fmt.Errorf(`ancestry derivation, %w`, err)
+	}
+	key := subtle.ConstantTimeCompare(node.Key, derived.Key)                // Compare the keys in constant time
+	fp := subtle.ConstantTimeCompare(node.Fingerprint, derived.Fingerprint) // Compare the fingerprints in constant time
+	return key&fp == 1, nil                                                 // Return the result of both comparisons
+}
+
+// Sibling derives a sibling of a key from a given hash and index, as the child key at the index of
+// the parent retained by keys derived with Params.RetainParent. The sibling is derived with the
+// parameters, context, and associated data of the key, and retains the same parent. Keys derived
+// without retaining their parent return an error.
+func (k *HDKey) Sibling(h func() hash.Hash, index uint32) (HDKey, error) {
+	if k.parent == nil {
+		return HDKey{}, errors.New(`sibling requires a key derived with a retained parent, see Params.RetainParent`)
+	}
+	key, err := k.parent.p.child(h, &k.parent.key, index, k.parent.ctx, k.parent.aad) // Derive the child of the retained parent
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`sibling, %w`, err)
+	}
+	return key, nil // Return the sibling HD key
+}
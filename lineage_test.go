@@ -0,0 +1,156 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestFindParent is a test for finding the parent of a key among candidates.
+func TestFindParent(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	candidates := make([]hdsk.HDKey, 0, 4)
+	candidates = append(candidates, master)
+	for i := range uint32(3) {
+		child, err := hdsk.Child(h, &master, i) // Derive a sibling candidate at index i
+		if err != nil {
+			t.Fatal(err)
+		}
+		candidates = append(candidates, child)
+	}
+	grandchild, err := hdsk.Child(h, &candidates[2], 7) // Derive a child of the candidate at index 2
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, ok, err := hdsk.FindParent(h, &grandchild, candidates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || index != 2 {
+		t.Fatalf(`expected parent at index 2, got %d (found %v)`, index, ok)
+	}
+	index, ok, err = hdsk.FindParent(h, &grandchild, candidates[3:]) // Search candidates without the parent
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || index != -1 {
+		t.Fatalf(`expected no parent, got %d (found %v)`, index, ok)
+	}
+}
+
+// TestVerifyChildren is a test for verifying the lineage of many keys.
+func TestVerifyChildren(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	children := make([]hdsk.HDKey, 0, 4)
+	for i := range uint32(3) {
+		child, err := hdsk.Child(h, &master, i) // Derive a child at index i
+		if err != nil {
+			t.Fatal(err)
+		}
+		children = append(children, child)
+	}
+	grandchild, err := hdsk.Child(h, &children[0], 0) // Derive a key that is not a child of master
+	if err != nil {
+		t.Fatal(err)
+	}
+	children = append(children, grandchild)
+	results, err := hdsk.VerifyChildren(h, &master, children)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []bool{true, true, true, false}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf(`lineage of child %d: expected %v, got %v`, i, expected[i], results[i])
+		}
+	}
+	children[1].Fingerprint = children[1].Fingerprint[:8] // Truncate a fingerprint
+	if _, err := hdsk.VerifyChildren(h, &master, children); err == nil {
+		t.Fatalf(`expected error for truncated fingerprint`)
+	}
+}
+
+// TestAncestry is a test for verifying the ancestry of a key along a derivation path.
+func TestAncestry(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 7}
+	node, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := hdsk.Ancestry(h, &node, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf(`expected node to descend from master along the path`)
+	}
+	ok, err = hdsk.Ancestry(h, &node, &master, hdsk.HDPath{42, 0, 1, 7}) // Path without the hardened index
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf(`expected no ancestry along a different path`)
+	}
+	other, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err = hdsk.Ancestry(h, &node, &other, path) // Different master key
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf(`expected no ancestry from a different master key`)
+	}
+}
+
+// TestSibling is a test for deriving siblings of keys with a retained parent.
+func TestSibling(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	p := hdsk.Params{RetainParent: true}
+	node, err := p.Node(h, &master, hdsk.HDPath{42, 0, 1, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint32{6, 5, 0, 3 | hdsk.Hardened} {
+		sibling, err := node.Sibling(h, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, index})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sibling.Equal(&expected) {
+			t.Fatalf(`mismatch for sibling %d`, index)
+		}
+		again, err := sibling.Sibling(h, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !again.Equal(&node) {
+			t.Fatalf(`expected siblings to retain the same parent`)
+		}
+	}
+	clone := node.Clone()
+	node.Zero()
+	if _, err := node.Sibling(h, 6); err == nil {
+		t.Fatalf(`expected error for sibling of a wiped key`)
+	}
+	if _, err := clone.Sibling(h, 6); err != nil {
+		t.Fatalf(`expected the clone to keep its retained parent, got %v`, err)
+	}
+	plain, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Sibling(h, 6); err == nil {
+		t.Fatalf(`expected error for sibling without a retained parent`)
+	}
+}
@@ -0,0 +1,21 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+)
+
+// MigrationImpact derives the fingerprints of a node under an old and a new schema, from a given
+// hash, master key, old schema, new schema, and derivation path. Matching fingerprints indicate
+// that migrating between the schemas leaves the node for the derivation path unchanged.
+func MigrationImpact(h func() hash.Hash, master *HDKey, oldSchema, newSchema HDSchema, pathStr string) (oldFP, newFP []byte, err error) {
+	oldFP, err = ExpectedFingerprint(h, master, oldSchema, pathStr) // Derive the fingerprint under the old schema
+	if err != nil {
+		return nil, nil, fmt.Errorf(`old schema, %w`, err)
+	}
+	newFP, err = ExpectedFingerprint(h, master, newSchema, pathStr) // Derive the fingerprint under the new schema
+	if err != nil {
+		return nil, nil, fmt.Errorf(`new schema, %w`, err)
+	}
+	return oldFP, newFP, nil // Return the fingerprints of both nodes
+}
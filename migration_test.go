@@ -0,0 +1,41 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestMigrationImpact is a test for comparing nodes between schemas.
+func TestMigrationImpact(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	oldSchema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relabeled, err := hdsk.Schema("m / app: any / purpose: any / context: any / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retyped, err := hdsk.Schema("m / application: str / purpose: any / context: any / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldFP, newFP, err := hdsk.MigrationImpact(h, &master, oldSchema, relabeled, hdsk.DefaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(oldFP, newFP) {
+		t.Fatalf(`relabeling segments must not change the node`)
+	}
+	oldFP, newFP, err = hdsk.MigrationImpact(h, &master, oldSchema, retyped, hdsk.DefaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(oldFP, newFP) {
+		t.Fatalf(`retyping a numeric segment as a string must change the node`)
+	}
+}
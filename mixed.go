@@ -0,0 +1,30 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+)
+
+// NodeMixed derives a new key at a node in a hierarchy descending from a master key, from given
+// hashes, master key, and derivation path, deriving the child key for each index of the path with
+// the hash at the same position, so that hashes[i] derives the key for path[i]. There must be a
+// hash for every index of the path. Keys derived with mixed hashes differ from keys derived with
+// Node unless every hash is the same, and can only be reproduced with the same hashes in order.
+// An empty derivation path returns a copy of the master key.
+func NodeMixed(hashes []func() hash.Hash, master *HDKey, path HDPath) (HDKey, error) {
+	if len(hashes) < len(path) {
+		return HDKey{}, fmt.Errorf(`mixed node requires a hash for each of %d indices, got %d`, len(path), len(hashes))
+	}
+	for i, h := range hashes[:len(path)] {
+		if h == nil {
+			return HDKey{}, fmt.Errorf(`mixed node missing hash at position %d`, i)
+		}
+	}
+	key, err := Params{}.walk(master, len(path), func(parent *HDKey, i int) (HDKey, error) {
+		return Child(hashes[i], parent, path[i]) // Derive a child of the parent with the hash for the current index
+	}, nil)
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`mixed node, %w`, err)
+	}
+	return key, nil // Return the HD key
+}
@@ -0,0 +1,57 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestNodeMixed is a test for deriving nodes with a hash for each index.
+func TestNodeMixed(t *testing.T) {
+	h1, h2 := sha512.New, sha256.New
+	master := testMaster(t, h1)
+	path := hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 0}
+	same, err := hdsk.NodeMixed([]func() hash.Hash{h1, h1, h1, h1}, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := hdsk.Node(h1, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same.Equal(&node) {
+		t.Fatalf(`expected the same node as Node for a single hash`)
+	}
+	mixed, err := hdsk.NodeMixed([]func() hash.Hash{h1, h1, h2, h2}, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boundary, err := hdsk.Node(h1, &master, path[:2]) // Derive the boundary node with the first hash
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Node(h2, &boundary, path[2:]) // Derive past the boundary with the second hash
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mixed.Equal(&expected) || mixed.Equal(&node) {
+		t.Fatalf(`expected the node derived with each hash past the boundary`)
+	}
+	for _, hashes := range [][]func() hash.Hash{nil, {h1, h1, h2}, {h1, nil, h2, h2}} {
+		if _, err := hdsk.NodeMixed(hashes, &master, path); err == nil {
+			t.Fatalf(`expected error for %d hashes`, len(hashes))
+		}
+	}
+	root, err := hdsk.NodeMixed(nil, &master, nil)
+	if err != nil || !root.Equal(&master) {
+		t.Fatalf(`expected the master key for an empty path, got %v`, err)
+	}
+	expected = master.Clone()
+	root.Zero()
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected a copy of the master key for an empty path`)
+	}
+}
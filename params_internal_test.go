@@ -0,0 +1,83 @@
+package hdsk
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"testing"
+)
+
+// TestInfoSeparator is a test for separating components of HKDF info.
+func TestInfoSeparator(t *testing.T) {
+	p := Params{}
+	if p.info("A", "1") != p.info("A1") {
+		t.Fatalf(`expected concatenated components without a separator`)
+	}
+	p = Params{Separate: true}
+	if p.info("A", "1") == p.info("A1") {
+		t.Fatalf(`separated components must not collide`)
+	}
+	if p.info("A", "1") != "A\x001" {
+		t.Fatalf(`expected default separator 0x00, got %q`, p.info("A", "1"))
+	}
+	p = Params{Separate: true, Separator: '|'}
+	if p.info("CHILD", "1") != "CHILD|1" {
+		t.Fatalf(`expected configured separator, got %q`, p.info("CHILD", "1"))
+	}
+}
+
+// TestInfoIndex is a test for the ambiguity of decimal index encodings in HKDF info.
+func TestInfoIndex(t *testing.T) {
+	p := Params{}
+	if p.info("CHILD", p.index(1), "2") != p.info("CHILD", p.index(12)) {
+		t.Fatalf(`expected decimal index 1 followed by 2 to encode as index 12`)
+	}
+	if len(p.index(1)) == len(p.index(12)) {
+		t.Fatalf(`expected decimal indices of varying length`)
+	}
+	p = Params{BinaryIndex: true}
+	for _, index := range []uint32{0, 1, 12, 0x7FFFFFFF} {
+		if len(p.index(index)) != 4 {
+			t.Fatalf(`expected 4 byte index encoding for %d, got %d bytes`, index, len(p.index(index)))
+		}
+	}
+	if p.info("CHILD", p.index(1), "2") == p.info("CHILD", p.index(12)) {
+		t.Fatalf(`binary indices must not collide`)
+	}
+	p.Hardening = true
+	_, _, info := p.childInfo(12|Hardened, nil)
+	if info != "HARDENED\x00\x00\x00\x0c" {
+		t.Fatalf(`unexpected info %q`, info)
+	}
+}
+
+// tiny is a hash truncated to a single byte, so that HKDF expands at most 255 bytes with it.
+type tiny struct{ hash.Hash }
+
+// Size returns the size of the truncated hash.
+func (tiny) Size() int { return 1 }
+
+// Sum appends the truncated hash to b.
+func (t tiny) Sum(b []byte) []byte { return append(b, t.Hash.Sum(nil)[0]) }
+
+// TestExpandLength is a test for the maximum length of expands in each expand mode.
+func TestExpandLength(t *testing.T) {
+	h := func() hash.Hash { return tiny{sha256.New()} }
+	secret, salt := make([]byte, 32), make([]byte, 16)
+	if _, err := (Params{}).expand(h, secret, salt, "INFO", 200, 55); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (Params{}).expand(h, secret, salt, "INFO", 200, 56); !errors.Is(err, ErrOutputTooLong) {
+		t.Fatalf(`expected %v, got %v`, ErrOutputTooLong, err)
+	}
+	ikm, err := Params{SplitExpand: true}.expand(h, secret, salt, "INFO", 255, 255)
+	if err != nil {
+		t.Fatalf(`expected the key and chain code to be checked separately in split expand mode, got %v`, err)
+	}
+	if len(ikm) != 510 {
+		t.Fatalf(`expected 510 bytes, got %d`, len(ikm))
+	}
+	if _, err := (Params{SplitExpand: true}).expand(h, secret, salt, "INFO", 16, 256); !errors.Is(err, ErrOutputTooLong) {
+		t.Fatalf(`expected %v, got %v`, ErrOutputTooLong, err)
+	}
+}
@@ -0,0 +1,353 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestParamsSeparator is a test for deriving keys with separated HKDF info.
+func TestParamsSeparator(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1, 0}
+	node, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, err := hdsk.Params{}.Node(h, &master, path) // Derive with the zero value parameters
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(node.Key, zero.Key) {
+		t.Fatalf(`zero value parameters must derive the same node`)
+	}
+	separated, err := hdsk.Params{Separate: true}.Node(h, &master, path) // Derive with the default separator
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(node.Key, separated.Key) {
+		t.Fatalf(`separated info must derive a different node`)
+	}
+}
+
+// TestParamsSeparatorKeys is a test for deriving keys of the key helpers with separated HKDF info.
+func TestParamsSeparatorKeys(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	label := []byte("label")
+	for _, c := range []struct {
+		name   string
+		method func() ([]byte, error)
+		derive func(p hdsk.Params) ([]byte, error)
+	}{
+		{"subkey", func() ([]byte, error) { return master.SubKey(h, label, 32) }, func(p hdsk.Params) ([]byte, error) { return p.SubKey(h, &master, label, 32) }},
+		{"leaf", func() ([]byte, error) { return master.Finalize(h, label) }, func(p hdsk.Params) ([]byte, error) { return p.Finalize(h, &master, label) }},
+		{"versioned", func() ([]byte, error) { return master.VersionedKey(h, 1, 32) }, func(p hdsk.Params) ([]byte, error) { return p.VersionedKey(h, &master, 1, 32) }},
+		{"jwt", func() ([]byte, error) { return master.JWTKey(h, label) }, func(p hdsk.Params) ([]byte, error) { return p.JWTKey(h, &master, label) }},
+	} {
+		method, err := c.method()
+		if err != nil {
+			t.Fatal(err)
+		}
+		zero, err := c.derive(hdsk.Params{}) // Derive with the zero value parameters
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(method, zero) {
+			t.Fatalf(`%s: zero value parameters must derive the same key as the key method`, c.name)
+		}
+		separated, err := c.derive(hdsk.Params{Separate: true}) // Derive with the default separator
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(zero, separated) {
+			t.Fatalf(`%s: separated info must derive a different key`, c.name)
+		}
+	}
+}
+
+// TestParamsLengths is a test for deriving keys and chain codes of configured lengths.
+func TestParamsLengths(t *testing.T) {
+	h := sha256.New
+	p := hdsk.Params{AllowWeak: true, KeyLen: 64}
+	master, err := p.Master(h, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := p.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []hdsk.HDKey{master, child} {
+		if len(k.Key) != 64 || len(k.Code) != 32 {
+			t.Fatalf(`expected 64 byte key and 32 byte chain code, got %d and %d`, len(k.Key), len(k.Code))
+		}
+	}
+	lineage, err := hdsk.Lineage(h, &child, &master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lineage {
+		t.Fatalf(`invalid lineage for child with 64 byte key`)
+	}
+	def := testMaster(t, h)
+	if !bytes.Equal(def.Key, master.Key[:32]) {
+		t.Fatalf(`expected the default key as a prefix of the longer key`)
+	}
+	for _, p := range []hdsk.Params{{KeyLen: 8}, {CodeLen: 256}, {KeyLen: -1}} {
+		if _, err := p.Child(h, &def, 0); err == nil {
+			t.Fatalf(`expected error for lengths %d and %d`, p.KeyLen, p.CodeLen)
+		}
+	}
+}
+
+// TestParamsDomain is a test for deriving keys with an application domain.
+func TestParamsDomain(t *testing.T) {
+	h := sha256.New
+	secret := bytes.Repeat([]byte{0x42, 0x24}, 16)
+	path := hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 0}
+	derive := func(domain string) hdsk.HDKey {
+		d := hdsk.NewDeriver(h)
+		d.Params.Domain = domain
+		master, err := d.Master(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := d.Node(&master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	master, err := hdsk.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	none := derive("")
+	if !none.Equal(&expected) {
+		t.Fatalf(`empty domain must derive the same node`)
+	}
+	a, b := derive("app-a"), derive("app-b")
+	if a.Equal(&none) || a.Equal(&b) {
+		t.Fatalf(`distinct domains must derive distinct nodes`)
+	}
+	again := derive("app-a")
+	if !a.Equal(&again) {
+		t.Fatalf(`the same domain must derive the same node`)
+	}
+	other, err := hdsk.Params{Domain: "app-a"}.Child(h, &master, 0) // Child of a master key without a domain
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Equal(&child) {
+		t.Fatalf(`domain must be mixed into child key derivation`)
+	}
+	if _, err := (hdsk.Params{Domain: strings.Repeat("a", 256)}).Master(h, secret); err == nil {
+		t.Fatalf(`expected error for domain exceeding 255 bytes`)
+	}
+}
+
+// TestParamsSaltLen is a test for deriving keys with longer salts.
+func TestParamsSaltLen(t *testing.T) {
+	h := sha512.New
+	secret := bytes.Repeat([]byte{0x42, 0x24}, 16)
+	master, err := hdsk.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := hdsk.Params{SaltLen: 16}.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !short.Equal(&master) {
+		t.Fatalf(`16 byte salts must derive the same master key`)
+	}
+	for _, length := range []int{32, 64} {
+		p := hdsk.Params{SaltLen: length}
+		long, err := p.Master(h, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if long.Equal(&master) {
+			t.Fatalf(`%d byte salts must derive a distinct master key`, length)
+		}
+		child, err := p.Child(h, &long, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		normal, err := hdsk.Child(h, &long, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.Equal(&normal) {
+			t.Fatalf(`%d byte salts must derive a distinct child key`, length)
+		}
+	}
+	for _, length := range []int{8, 65} {
+		if _, err := (hdsk.Params{SaltLen: length}).Master(h, secret); err == nil {
+			t.Fatalf(`expected error for %d byte salts`, length)
+		}
+	}
+	if _, err := (hdsk.Params{SaltLen: 64}).Master(sha256.New, secret); err == nil {
+		t.Fatalf(`expected error for salts exceeding the size of the hash`)
+	}
+}
+
+// TestParamsMalformedKey is a test for rejecting malformed master keys.
+func TestParamsMalformedKey(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	malformed := master.Clone()
+	malformed.Code = malformed.Code[:10] // Shorten the chain code to 10 bytes
+	if _, err := hdsk.Child(h, &malformed, 0); err == nil {
+		t.Fatalf(`expected error for a 10 byte chain code`)
+	}
+	if _, err := hdsk.Node(h, &malformed, hdsk.HDPath{42, 0}); err == nil {
+		t.Fatalf(`expected error for a 10 byte chain code`)
+	}
+	if _, err := hdsk.Node(h, &malformed, nil); err == nil {
+		t.Fatalf(`expected error for a 10 byte chain code with an empty path`)
+	}
+	long, err := hdsk.Params{AllowWeak: true, KeyLen: 48}.Master(h, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (hdsk.Params{KeyLen: 48}).Child(h, &long, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hdsk.Child(h, &long, 0); err == nil {
+		t.Fatalf(`expected error for a key of a different length than configured`)
+	}
+}
+
+// splitExpandVectors are HDSK test vectors for keys and chain codes derived in split expand mode.
+var splitExpandVectors = []vector{
+	{
+		path: "m/42/0/1/0",
+		key:  "dc79f83c3681a3d2a5058d4738d4f75f537c59976b1c05c2e7b1a3b22bde5d74",
+	},
+	{
+		path: "m/42/0/1/1",
+		key:  "c1883fb0084ef5e982976404a149e7bc08fe568a0093accd2bbea5f226a0f4c4",
+	},
+	{
+		path: "m/42/0/1/2",
+		key:  "c0f5d06db4791896dcb451626ef8c783ec3d38fe11f53af3cca44802a1892ac2",
+	},
+	{
+		path: "m/42/0/1'/0",
+		key:  "1e97e5a74b8912009e1bc31c190f4f74a034bbf775ee6bbcb4ef53cd6bc427c6",
+	},
+}
+
+// TestParamsSplitExpand is a test for deriving keys and chain codes in split expand mode.
+func TestParamsSplitExpand(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := hdsk.NewDeriver(h)
+	d.Params = hdsk.Params{AllowWeak: true, SplitExpand: true, Hardening: true}
+	master, err := d.Master(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := testMaster(t, h)
+	if bytes.Equal(master.Key, def.Key) || bytes.Equal(master.Code, def.Code) {
+		t.Fatalf(`split expand mode must derive a different master key`)
+	}
+	for _, v := range splitExpandVectors {
+		path, err := d.Path(v.path, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := d.Node(&master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key := hex.EncodeToString(node.Key); key != v.key {
+			t.Fatalf(`mismatch for %s: expected %q, got %q`, v.path, v.key, key)
+		}
+	}
+	long, err := hdsk.Params{AllowWeak: true, SplitExpand: true, KeyLen: 64}.Master(h, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(long.Key[:32], master.Key) || !bytes.Equal(long.Code, master.Code) {
+		t.Fatalf(`split expand mode must derive chain codes independent of the key length`)
+	}
+}
+
+// binaryIndexVectors are HDSK test vectors for keys derived in binary index mode.
+var binaryIndexVectors = []vector{
+	{
+		path: "m/42/0/1/0",
+		key:  "2a6b2bb647a8b3682b897bf4dece6c7151e62b1cb018813b290f608164a859d1",
+	},
+	{
+		path: "m/42/0/1/1",
+		key:  "c22d66b9984011bd2910216f45882dd79501c80c0070c7b4d0c78237f380ff57",
+	},
+	{
+		path: "m/42/0/1/2",
+		key:  "bdf453c62bb80602f34a45ddd1a5f0875643faf98d3bc8ffcc5163e10e6194c8",
+	},
+	{
+		path: "m/42/0/1'/0",
+		key:  "b0920c400749899c9032939fa6ea13cd706ca04b0557d62433e81105e03c99ae",
+	},
+}
+
+// TestParamsBinaryIndex is a test for deriving child keys in binary index mode.
+func TestParamsBinaryIndex(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := hdsk.NewDeriver(h)
+	d.Params = hdsk.Params{AllowWeak: true, BinaryIndex: true, Hardening: true}
+	master, err := d.Master(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range binaryIndexVectors {
+		path, err := d.Path(v.path, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := d.Node(&master, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key := hex.EncodeToString(node.Key); key != v.key {
+			t.Fatalf(`mismatch for %s: expected %q, got %q`, v.path, v.key, key)
+		}
+	}
+	def, err := hdsk.Child(h, &master, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := d.Child(&master, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(child.Key, def.Key) {
+		t.Fatalf(`binary index mode must derive a different child key`)
+	}
+}
@@ -0,0 +1,83 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/bip39"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Argon2Params holds parameters for stretching passphrases with Argon2id. The zero value uses the
+// parameters recommended by RFC 9106 for memory constrained environments.
+type Argon2Params struct {
+	Time    uint32 // Number of passes over the memory, 3 by default.
+	Memory  uint32 // Memory in KiB, 64 MiB by default.
+	Threads uint8  // Degree of parallelism, 4 by default.
+}
+
+// MasterFromPassphrase derives a new master key from a given hash, passphrase, salt, and Argon2
+// parameters. The passphrase is stretched with Argon2id into a 32 byte secret for Master, and
+// the salt must be at least 16 bytes.
+func MasterFromPassphrase(h func() hash.Hash, passphrase, salt []byte, params Argon2Params) (HDKey, error) {
+	if len(salt) < 16 {
+		return HDKey{}, fmt.Errorf(`passphrase salt must be at least 16 bytes, got %d`, len(salt))
+	}
+	if params.Time == 0 {
+		params.Time = 3
+	}
+	if params.Memory == 0 {
+		params.Memory = 64 * 1024
+	}
+	if params.Threads == 0 {
+		params.Threads = 4
+	}
+	secret := argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, 32) // Stretch the passphrase
+	defer clear(secret)                                                                      // Wipe the stretched secret
+	key, err := Master(h, secret)                                                            // Derive the master key from the stretched secret
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`passphrase, %w`, err)
+	}
+	return key, nil // Return the master HD key
+}
+
+// MasterFromScrypt derives a new master key from a given hash, passphrase, salt, and scrypt cost
+// parameters. The passphrase is stretched with scrypt into a 32 byte secret, producing the same
+// master key as Master with the stretched secret. The cost parameter N must be a power of two.
+func MasterFromScrypt(h func() hash.Hash, passphrase, salt []byte, N, r, p int) (HDKey, error) {
+	if N <= 0 || N&(N-1) != 0 {
+		return HDKey{}, fmt.Errorf(`scrypt cost parameter must be a power of two, got %d`, N)
+	}
+	secret, err := scrypt.Key(passphrase, salt, N, r, p, 32) // Stretch the passphrase
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`scrypt, %w`, err)
+	}
+	defer clear(secret)           // Wipe the stretched secret
+	key, err := Master(h, secret) // Derive the master key from the stretched secret
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`passphrase, %w`, err)
+	}
+	return key, nil // Return the master HD key
+}
+
+// MasterFromMnemonic derives a new master key from a given hash, BIP39 mnemonic, and passphrase.
+// The mnemonic must be 12, 15, 18, 21, or 24 words of the English wordlist separated by single
+// spaces, with a valid checksum. The mnemonic is stretched into the standard 64 byte BIP39 seed,
+// and the whole seed is the secret for Master. The mnemonic and passphrase are not normalized, so
+// passphrases beyond ASCII must be given in Unicode NFKD form to match other implementations.
+func MasterFromMnemonic(h func() hash.Hash, mnemonic, passphrase string) (HDKey, error) {
+	if err := bip39.Validate(mnemonic); err != nil {
+		return HDKey{}, fmt.Errorf(`mnemonic, %w, %w`, ErrInvalidMnemonic, err)
+	}
+	seed, err := bip39.Seed(mnemonic, passphrase) // Stretch the mnemonic into the seed
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`mnemonic seed, %w`, err)
+	}
+	defer clear(seed)           // Wipe the seed
+	key, err := Master(h, seed) // Derive the master key from the seed
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`mnemonic, %w`, err)
+	}
+	return key, nil // Return the master HD key
+}
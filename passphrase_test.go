@@ -0,0 +1,109 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// TestMasterFromPassphrase is a test for deriving master keys from passphrases.
+func TestMasterFromPassphrase(t *testing.T) {
+	h := sha256.New
+	passphrase := []byte("correct horse battery staple")
+	salt := bytes.Repeat([]byte{0x5A}, 16)
+	params := hdsk.Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+	master, err := hdsk.MasterFromPassphrase(h, passphrase, salt, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Master(h, argon2.IDKey(passphrase, salt, 1, 8*1024, 1, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected master key from the stretched passphrase`)
+	}
+	other, err := hdsk.MasterFromPassphrase(h, passphrase, bytes.Repeat([]byte{0xA5}, 16), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Equal(&other) {
+		t.Fatalf(`expected distinct master keys for distinct salts`)
+	}
+	if _, err := hdsk.MasterFromPassphrase(h, passphrase, salt[:15], params); err == nil {
+		t.Fatalf(`expected error for short salt`)
+	}
+}
+
+// TestMasterFromScrypt is a test for deriving master keys from passphrases with scrypt.
+func TestMasterFromScrypt(t *testing.T) {
+	h := sha256.New
+	passphrase := []byte("correct horse battery staple")
+	salt := bytes.Repeat([]byte{0x5A}, 16)
+	master, err := hdsk.MasterFromScrypt(h, passphrase, salt, 1024, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := scrypt.Key(passphrase, salt, 1024, 8, 1, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected master key from the stretched passphrase`)
+	}
+	for _, n := range []int{0, -2, 1000} {
+		if _, err := hdsk.MasterFromScrypt(h, passphrase, salt, n, 8, 1); err == nil {
+			t.Fatalf(`expected error for cost parameter %d`, n)
+		}
+	}
+	if _, err := hdsk.MasterFromScrypt(h, passphrase, salt, 1, 8, 1); err == nil {
+		t.Fatalf(`expected scrypt error for cost parameter 1`)
+	}
+}
+
+// TestMasterFromMnemonic is a test for deriving master keys from BIP39 mnemonics.
+func TestMasterFromMnemonic(t *testing.T) {
+	h := sha256.New
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	master, err := hdsk.MasterFromMnemonic(h, mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Master(h, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected master key from the BIP39 seed`)
+	}
+	other, err := hdsk.MasterFromMnemonic(h, mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Equal(&other) {
+		t.Fatalf(`expected distinct master keys for distinct passphrases`)
+	}
+	for _, mnemonic := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abut",
+	} {
+		if _, err := hdsk.MasterFromMnemonic(h, mnemonic, ""); !errors.Is(err, hdsk.ErrInvalidMnemonic) {
+			t.Fatalf(`mnemonic %q: expected %v, got %v`, mnemonic, hdsk.ErrInvalidMnemonic, err)
+		}
+	}
+}
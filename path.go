@@ -0,0 +1,195 @@
+package hdsk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// String returns the derivation path as a string of decimal indices, as parsed by Path. String
+// indices are hashed when parsed, so they are returned as their hashed integers, and only
+// derivation paths of numeric indices reproduce the string they were parsed from. Use
+// Params.PathString for the apostrophe form of hardened indices.
+func (p HDPath) String() string {
+	return Params{}.PathString(p)
+}
+
+// PathString returns a given derivation path as a string, like HDPath.String, parsed back by the
+// Path method of the parameters. In hardened index mode, hardened indices are followed by an
+// apostrophe.
+func (p Params) PathString(path HDPath) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		if !p.Hardening {
+			b.WriteString("/" + strconv.FormatUint(uint64(index), 10)) // Add each index as a full 32 bit integer
+			continue
+		}
+		b.WriteString("/" + strconv.FormatUint(uint64(index&^Hardened), 10)) // Add each index without the hardened flag
+		if index&Hardened != 0 {
+			b.WriteString("'") // Mark hardened indices
+		}
+	}
+	return b.String()
+}
+
+// Bytes encodes the derivation path as concatenated big endian indices. The encoding is
+// comparable, and can be used as a map key when converted to a string.
+func (p HDPath) Bytes() []byte {
+	b := make([]byte, 0, 4*len(p)) // Allocate 4 bytes for each index
+	for _, index := range p {
+		b = binary.BigEndian.AppendUint32(b, index) // Append the bytes of each encoded index
+	}
+	return b
+}
+
+// PathFromBytes decodes a derivation path from concatenated big endian indices.
+func PathFromBytes(b []byte) (HDPath, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf(`encoded derivation path must be a multiple of 4 bytes, got %d`, len(b))
+	}
+	path := make(HDPath, 0, len(b)/4) // Allocate slice for the decoded path
+	for i := 0; i < len(b); i += 4 {
+		path = append(path, binary.BigEndian.Uint32(b[i:i+4])) // Decode each index
+	}
+	return path, nil // Return the decoded derivation path
+}
+
+// MarshalBinary encodes the derivation path in binary form, as the number of indices encoded as
+// an unsigned varint, followed by each index as 4 big endian bytes, including the hardened flag.
+func (p HDPath) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, binary.MaxVarintLen64+4*len(p)) // Allocate the count and 4 bytes for each index
+	b = binary.AppendUvarint(b, uint64(len(p)))          // Append the number of indices
+	for _, index := range p {
+		b = binary.BigEndian.AppendUint32(b, index) // Append the bytes of each encoded index
+	}
+	return b, nil // Return the binary encoded derivation path
+}
+
+// UnmarshalBinary decodes a derivation path from binary form.
+func (p *HDPath) UnmarshalBinary(data []byte) error {
+	count, n := binary.Uvarint(data) // Decode the number of indices
+	if n <= 0 {
+		return errors.New(`truncated binary derivation path`)
+	}
+	data = data[n:]
+	if count != uint64(len(data)/4) || len(data)%4 != 0 {
+		return fmt.Errorf(`binary derivation path of %d indices must have %d bytes of indices, got %d`, count, 4*count, len(data))
+	}
+	path, err := PathFromBytes(data) // Decode each index
+	if err != nil {
+		return err
+	}
+	*p = path
+	return nil
+}
+
+// Remaining returns the number of schema segments following the derivation path, which is zero
+// when the derivation path has an index for every segment of the schema.
+func (p HDPath) Remaining(schema HDSchema) int {
+	return max(len(schema)-len(p), 0)
+}
+
+// At returns the index of the derivation path for a given schema and label, and whether the
+// schema has a segment with the label that the derivation path reaches.
+func (p HDPath) At(s HDSchema, label string) (uint32, bool) {
+	i, ok := s.IndexOf(label) // Get the position of the label in the schema
+	if !ok || i >= len(p) {
+		return 0, false
+	}
+	return p[i], true
+}
+
+// StrToIndex maps a string to the 32 bit integer index it is parsed as by Path for segments of the
+// plain "str" type, from a given hash and string. It is the same mapping used by Path, so indices
+// can be precomputed for lookup tables of labels without parsing a derivation path.
+func StrToIndex(h func() hash.Hash, s string) (uint32, error) {
+	index, err := utils.StrToIndex(h, s) // Hash the string to an index
+	if err != nil {
+		return 0, fmt.Errorf(`string index, %w`, err)
+	}
+	return index, nil
+}
+
+// NormalizePath normalizes a derivation path string for parsing with Path, trimming whitespace
+// around each segment and dropping empty segments from consecutive or trailing slashes, so that
+// "m / 42 / 0//1/" normalizes to "m/42/0/1", and a path of the root alone normalizes to "m".
+// Normalization is opt-in, as string indices with surrounding whitespace hash to different
+// indices when parsed without normalization.
+func NormalizePath(str string) string {
+	segments := strings.Split(str, "/")
+	result := make([]string, 0, len(segments)) // Allocate slice for the normalized segments
+	for _, segment := range segments {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			result = append(result, segment) // Add each non-empty trimmed segment
+		}
+	}
+	return strings.Join(result, "/") // Return the normalized derivation path
+}
+
+// numeric checks if an index string consists only of decimal digits.
+func numeric(index string) bool {
+	if index == "" {
+		return false
+	}
+	for _, r := range index {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// cutHardened removes a hardened marker from an index string of a given type, returning the index
+// without its marker and whether the index is hardened. Apostrophe markers are only permitted on
+// numeric indices, and "h" markers are only recognized on indices of numeric types, so that
+// indices ending in "h" of any types are hashed as strings.
+func cutHardened(index, typ string) (string, bool, error) {
+	if trimmed, ok := strings.CutSuffix(index, "'"); ok {
+		switch {
+		case trimmed == "":
+			return "", false, errors.New(`hardened marker without index`)
+		case strings.Contains(trimmed, "'"):
+			return "", false, fmt.Errorf(`invalid hardened marker in index %q`, index)
+		case utils.BaseType(typ) == "str", utils.BaseType(typ) == "hex":
+			return "", false, fmt.Errorf(`hardened marker on %s index %q`, utils.BaseType(typ), index)
+		case !numeric(trimmed):
+			return "", false, fmt.Errorf(`hardened marker on non-numeric index %q`, index)
+		}
+		return trimmed, true, nil // Return the numeric index as hardened
+	}
+	if strings.Contains(index, "'") {
+		return "", false, fmt.Errorf(`invalid hardened marker in index %q`, index)
+	}
+	if trimmed, ok := strings.CutSuffix(index, "h"); ok && utils.BaseType(typ) == "num" && numeric(trimmed) {
+		return trimmed, true, nil // Return the numeric index as hardened
+	}
+	return index, false, nil // Return the index unchanged
+}
+
+// AnnotatedIndex is an index of a derivation path with the label of its schema segment and the
+// raw string it was parsed from.
+type AnnotatedIndex struct {
+	Label string // Label of the schema segment.
+	Raw   string // Index as written in the derivation path.
+	Index uint32 // Parsed index.
+}
+
+// PathAnnotated parses a new derivation path from a given hash, string, and schema, like Path,
+// returning each index with the label of its schema segment and the raw string it was parsed from,
+// such as for displaying derivation paths as entered. Errors match the errors of Path.
+func PathAnnotated(h func() hash.Hash, str string, schema HDSchema) ([]AnnotatedIndex, error) {
+	result := make([]AnnotatedIndex, 0, strings.Count(str, "/")) // Allocate slice for the annotated path
+	err := walkPath(h, str, schema, utils.GetIndex, 0, func(label, raw string, idx uint32) {
+		result = append(result, AnnotatedIndex{Label: label, Raw: raw, Index: idx})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil // Return the annotated derivation path
+}
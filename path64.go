@@ -0,0 +1,71 @@
+package hdsk
+
+import (
+	"encoding/binary"
+	"hash"
+	"strconv"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// HDPath64 is a derivation path of 64 bit indices.
+type HDPath64 []uint64
+
+// Hardened64 is the flag marking a 64 bit index for hardened child key derivation. With
+// Params.Hardening set, indices with the flag set derive hardened child keys, and indices without
+// it derive normal child keys.
+const Hardened64 uint64 = 1 << 63
+
+// Path64 parses a new derivation path of 64 bit indices from a given hash, string, and schema,
+// like Path, with string indices hashed to 64 bit integers from the first eight bytes of the hash.
+// Hardened markers are only parsed with Params.Path64, see Params.
+func Path64(h func() hash.Hash, str string, schema HDSchema) (HDPath64, error) {
+	return Params{}.Path64(h, str, schema)
+}
+
+// Path64 parses a new derivation path of 64 bit indices from a given hash, string, and schema,
+// like Path64, using the parameters. In hardened index mode, indices are limited to 63 bits, and
+// hardened markers are parsed as by Params.Path, flagging indices with Hardened64.
+func (p Params) Path64(h func() hash.Hash, str string, schema HDSchema) (HDPath64, error) {
+	if !p.Hardening {
+		return parsePath(h, str, schema, utils.GetIndex64, 0) // Parse 64 bit indices without hardened markers
+	}
+	return parsePath(h, str, schema, utils.GetIndex63, Hardened64) // Parse 63 bit indices with hardened markers
+}
+
+// Child64 derives a new child key from a given hash, master key, and 64 bit index. The index is
+// encoded as 8 bytes, so the child key differs from a child key derived with Child for the same
+// numeric index.
+func Child64(h func() hash.Hash, master *HDKey, index uint64) (HDKey, error) {
+	return Params{}.Child64(h, master, index)
+}
+
+// Child64 derives a new child key from a given hash, master key, and 64 bit index, like Child64,
+// using the parameters. In hardened index mode, indices flagged by Hardened64 derive hardened
+// child keys.
+func (p Params) Child64(h func() hash.Hash, master *HDKey, index uint64) (HDKey, error) {
+	info1 := make([]byte, 8)
+	binary.BigEndian.PutUint64(info1, index)         // Context info from bytes of encoded index
+	hardened := p.Hardening && index&Hardened64 != 0 // Only flagged indices in hardened index mode are hardened
+	tag := "CHILD64"
+	if hardened {
+		tag, index = "HARDENED64", index&^Hardened64 // Encode hardened indices without the flag
+	}
+	info2 := p.info(tag, strconv.FormatUint(index, 10)) // Construct info for HKDF from CHILD64 or HARDENED64 + index string
+	return p.derive(h, master, hardened, info1, info2, nil)
+}
+
+// Node64 derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, and derivation path of 64 bit indices. An empty derivation path returns a
+// copy of the master key.
+func Node64(h func() hash.Hash, master *HDKey, path HDPath64) (HDKey, error) {
+	return Params{}.Node64(h, master, path)
+}
+
+// Node64 derives a new key at a node in a hierarchy descending from a master key, from a given
+// hash, master key, and derivation path of 64 bit indices, like Node64, using the parameters.
+func (p Params) Node64(h func() hash.Hash, master *HDKey, path HDPath64) (HDKey, error) {
+	return p.walk(master, len(path), func(parent *HDKey, i int) (HDKey, error) {
+		return p.Child64(h, parent, path[i]) // Derive a child of the parent for the current index
+	}, nil)
+}
@@ -0,0 +1,136 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// vectors64 are HDSK test vectors for derivation paths of 64 bit indices.
+var vectors64 = []struct {
+	p    hdsk.Params
+	path hdsk.HDPath64
+	key  string
+}{
+	{
+		path: hdsk.HDPath64{42, 0, 1, 0},
+		key:  "842d3a5d85edcee4bc1994363dff8a3c4dc3915293c61494462031b68326a01a",
+	},
+	{
+		p:    hdsk.Params{Hardening: true},
+		path: hdsk.HDPath64{42 | hdsk.Hardened64, 0, 1, 1 << 40},
+		key:  "f52d99e82f0326d3167b33ccf4b7a2e0bd28ec9fb50c4393c17a1c761bcf952b",
+	},
+	{
+		path: hdsk.HDPath64{42 | hdsk.Hardened64, 0, 1, 1 << 40},
+		key:  "e01439fa1a145becca23d48a4871ce2797dc683565b17f4d686ba3d5fb62673f",
+	},
+}
+
+// TestNode64 is a test for deriving nodes of 64 bit indices.
+func TestNode64(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	for _, v := range vectors64 {
+		node, err := v.p.Node64(h, &master, v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(node.Key) != v.key {
+			t.Fatalf(`path %v: expected %s, got %x`, v.path, v.key, node.Key)
+		}
+	}
+	node32, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(node32.Key) == vectors64[0].key {
+		t.Fatalf(`expected 32 and 64 bit derivation to differ for the same numeric indices`)
+	}
+	root, err := hdsk.Node64(h, &master, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := master.Clone()
+	root.Zero()
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected a copy of the master key for an empty path`)
+	}
+	if _, err := hdsk.Node64(h, &master, make(hdsk.HDPath64, hdsk.MaxDepth+1)); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+	malformed := hdsk.HDKey{Key: master.Key, Code: master.Code[:16]}
+	if _, err := hdsk.Node64(h, &malformed, nil); err == nil {
+		t.Fatalf(`expected error for malformed master key`)
+	}
+}
+
+// TestPath64 is a test for parsing derivation paths of 64 bit indices.
+func TestPath64(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path64(h, "m/42/vault/1/18446744073709551615", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0] != 42 || path[3] != 1<<64-1 {
+		t.Fatalf(`unexpected 64 bit path %v`, path)
+	}
+	path32, err := hdsk.Path(h, "m/42/vault", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[1]>>32 != uint64(path32[1]) {
+		t.Fatalf(`expected string indices hashed to 64 bits from the first eight bytes of the hash`)
+	}
+	if _, err := hdsk.Path64(h, "m/42/0/1/18446744073709551616", schema); err == nil {
+		t.Fatalf(`expected error for index outside of the 64 bit range`)
+	}
+	p := hdsk.Params{Hardening: true}
+	hardened, err := p.Path64(h, "m/42'/vault/1/1099511627776", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hardened[0] != 42|hdsk.Hardened64 || hardened[3] != 1<<40 {
+		t.Fatalf(`unexpected hardened 64 bit path %v`, hardened)
+	}
+	if hardened[1] != path[1]&^hdsk.Hardened64 {
+		t.Fatalf(`expected string indices hashed to 63 bits in hardened index mode`)
+	}
+	if _, err := p.Path64(h, "m/42/0/1/9223372036854775808", schema); err == nil {
+		t.Fatalf(`expected error for index outside of the non-hardened range`)
+	}
+}
+
+// TestChild64Hardening is a test for deriving 64 bit indices of 2^63 and above in both modes.
+func TestChild64Hardening(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	index := 42 | hdsk.Hardened64
+	normal, err := hdsk.Child64(h, &master, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hardened, err := hdsk.Params{Hardening: true}.Child64(h, &master, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if normal.Equal(&hardened) {
+		t.Fatalf(`expected the flagged index to derive a hardened child only in hardened index mode`)
+	}
+	codeOnly := hdsk.HDKey{Key: make([]byte, 32), Code: master.Code, Depth: master.Depth}
+	forged, err := hdsk.Child64(h, &codeOnly, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(forged.Key, normal.Key) {
+		t.Fatalf(`expected the index to derive a normal child from the chain code alone in the default mode`)
+	}
+}
@@ -0,0 +1,345 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestPathBytes is a test for encoding derivation paths as bytes.
+func TestPathBytes(t *testing.T) {
+	path := hdsk.HDPath{42, 0, 1, 0xFFFFFFFF}
+	b := path.Bytes()
+	if len(b) != 16 {
+		t.Fatalf(`expected 16 bytes, got %d`, len(b))
+	}
+	decoded, err := hdsk.PathFromBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(path, decoded) {
+		t.Fatalf(`expected %v, got %v`, path, decoded)
+	}
+	seen := map[string]bool{string(path.Bytes()): true} // Use the encoded path as a map key
+	if !seen[string(decoded.Bytes())] {
+		t.Fatalf(`equal paths must share a map key`)
+	}
+	if seen[string(hdsk.HDPath{42, 0, 1}.Bytes())] {
+		t.Fatalf(`distinct paths must not share a map key`)
+	}
+	empty, err := hdsk.PathFromBytes(nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf(`expected empty path, got %v, %v`, empty, err)
+	}
+	if _, err := hdsk.PathFromBytes(b[:15]); err == nil {
+		t.Fatalf(`expected error for truncated bytes`)
+	}
+}
+
+// TestPathBinary is a test for encoding derivation paths in binary form.
+func TestPathBinary(t *testing.T) {
+	for _, path := range []hdsk.HDPath{{}, {42, 0, 1, 0}, {42 | hdsk.Hardened, 0, 1 | hdsk.Hardened, 0xFFFFFFFF}, make(hdsk.HDPath, 200)} {
+		b, err := path.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded hdsk.HDPath
+		if err := decoded.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(path, decoded) {
+			t.Fatalf(`expected %v, got %v`, path, decoded)
+		}
+	}
+	b, err := hdsk.HDPath{42, 0, 1, 0}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 17 {
+		t.Fatalf(`expected 17 bytes, got %d`, len(b))
+	}
+	var decoded hdsk.HDPath
+	for _, data := range [][]byte{nil, b[:16], b[:1], append(b, 0), {0x80}, {0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}} {
+		if err := decoded.UnmarshalBinary(data); err == nil {
+			t.Fatalf(`expected error for %x`, data)
+		}
+	}
+}
+
+// TestPathHardened is a test for parsing hardened indices in derivation paths.
+func TestPathHardened(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: any / purpose: num / context: str / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := hdsk.Params{Hardening: true}
+	path, err := p.Path(h, "m/42'/0h/1/0", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context, err := p.Path(h, "m/0/0/1", schema) // Parse the string context index
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := hdsk.HDPath{42 | hdsk.Hardened, 0 | hdsk.Hardened, context[2], 0}
+	if !slices.Equal(path, expected) {
+		t.Fatalf(`expected %v, got %v`, expected, path)
+	}
+	bath, err := p.Path(h, "m/bath", schema) // Parse a string ending in h
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bath[0]&hdsk.Hardened != 0 {
+		t.Fatalf(`string index ending in h must not be hardened`)
+	}
+	str, err := hdsk.StrToIndex(h, "42h") // Hash the numeric index ending in h as a string
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		p        hdsk.Params
+		expected uint32
+	}{
+		{hdsk.Params{}, str},
+		{p, str &^ hdsk.Hardened},
+	} {
+		parsed, err := c.p.Path(h, "m/42h", schema) // Parse a numeric index ending in h on an any segment
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed[0] != c.expected {
+			t.Fatalf(`expected index ending in h on an any segment to be hashed as a string, got %d`, parsed[0])
+		}
+	}
+	for _, str := range []string{
+		"m/'",              // Lone apostrophe
+		"m/42''",           // Double apostrophe
+		"m/4'2",            // Apostrophe inside index
+		"m/abc'",           // Apostrophe on non-numeric index
+		"m/42/0/1'",        // Apostrophe on string segment
+		"m/42/2147483648'", // Numeric index outside of non-hardened range
+	} {
+		if _, err := p.Path(h, str, schema); err == nil {
+			t.Fatalf(`expected error for %s`, str)
+		}
+	}
+	if _, err := hdsk.Path(h, "m/42/0/1/0'", schema); err == nil {
+		t.Fatalf(`expected error for a hardened marker outside of hardened index mode`)
+	}
+}
+
+// TestPathString is a test for the string form of derivation paths.
+func TestPathString(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := hdsk.Params{Hardening: true}
+	for _, str := range []string{"m", hdsk.DefaultPath, "m/42'/0/1'/0", "m/2147483647'/2147483647"} {
+		path, err := p.Path(h, str, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.PathString(path) != str {
+			t.Fatalf(`expected %q, got %q`, str, p.PathString(path))
+		}
+		parsed, err := p.Path(h, p.PathString(path), schema) // Parse the string form of the path
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(path, parsed) {
+			t.Fatalf(`expected %v to round trip, got %v`, path, parsed)
+		}
+	}
+	if s := p.PathString(hdsk.HDPath{42 | hdsk.Hardened, 0}); s != "m/42'/0" {
+		t.Fatalf(`expected %q, got %q`, "m/42'/0", s)
+	}
+	if s := (hdsk.HDPath{42 | hdsk.Hardened, 0}).String(); s != "m/2147483690/0" {
+		t.Fatalf(`expected %q, got %q`, "m/2147483690/0", s)
+	}
+	numeric, err := hdsk.Schema("m / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path(h, "m/3000000000", numeric) // An index of 2^31 or above in the default mode
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.String() != "m/3000000000" || fmt.Sprintf("%v", path) != "m/3000000000" {
+		t.Fatalf(`expected %q, got %q`, "m/3000000000", path.String())
+	}
+	if parsed, err := hdsk.Path(h, path.String(), numeric); err != nil || !slices.Equal(path, parsed) {
+		t.Fatalf(`expected %v to round trip, got %v, %v`, path, parsed, err)
+	}
+	hardened := hdsk.HDPath{852516352 | hdsk.Hardened} // The same index in hardened index mode
+	if s := p.PathString(hardened); s != "m/852516352'" {
+		t.Fatalf(`expected %q, got %q`, "m/852516352'", s)
+	}
+	if parsed, err := p.Path(h, p.PathString(hardened), numeric); err != nil || !slices.Equal(hardened, parsed) {
+		t.Fatalf(`expected %v to round trip, got %v, %v`, hardened, parsed, err)
+	}
+}
+
+// TestParsePathStrict is a test for parsing derivation paths with an index for every segment.
+func TestParsePathStrict(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.ParsePathStrict(h, hdsk.DefaultPath, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.Remaining(schema) != 0 {
+		t.Fatalf(`expected no remaining segments, got %d`, path.Remaining(schema))
+	}
+	prefix, err := hdsk.Path(h, "m/42/0", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix.Remaining(schema) != 2 {
+		t.Fatalf(`expected 2 remaining segments, got %d`, prefix.Remaining(schema))
+	}
+	if _, err := hdsk.ParsePathStrict(h, "m/42/0", schema); err == nil {
+		t.Fatalf(`expected error for missing indices`)
+	}
+	optional, err := hdsk.Schema("m / application: any / index?: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hdsk.ParsePathStrict(h, "m/42", optional); err == nil {
+		t.Fatalf(`expected error for missing optional index`)
+	}
+}
+
+// TestPathAt is a test for looking up indices of derivation paths by label.
+func TestPathAt(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, ok := schema.IndexOf("context"); !ok || i != 2 {
+		t.Fatalf(`expected label at position 2, got %d (found %v)`, i, ok)
+	}
+	if i, ok := schema.IndexOf("missing"); ok || i != -1 {
+		t.Fatalf(`expected no position for a missing label, got %d (found %v)`, i, ok)
+	}
+	path, err := hdsk.Path(h, "m/42/0/7", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index, ok := path.At(schema, "context"); !ok || index != 7 {
+		t.Fatalf(`expected index 7, got %d (found %v)`, index, ok)
+	}
+	if _, ok := path.At(schema, "index"); ok {
+		t.Fatalf(`expected no index for a label beyond the path`)
+	}
+	if _, ok := path.At(schema, "missing"); ok {
+		t.Fatalf(`expected no index for a missing label`)
+	}
+}
+
+// TestNormalizePath is a test for normalizing derivation path strings.
+func TestNormalizePath(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Path(h, hdsk.DefaultPath, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, str := range []string{"m / 42 / 0 / 1 / 0", "m/42/0//1/0", "m/42/0/1/0/", " m//42 /0/ 1/0// "} {
+		normalized := hdsk.NormalizePath(str)
+		if normalized != hdsk.DefaultPath {
+			t.Fatalf(`path %q: expected %q, got %q`, str, hdsk.DefaultPath, normalized)
+		}
+		path, err := hdsk.Path(h, normalized, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(path, expected) {
+			t.Fatalf(`path %q: expected %v, got %v`, str, expected, path)
+		}
+	}
+	for _, str := range []string{"m", "m/", " m // "} {
+		path, err := hdsk.Path(h, hdsk.NormalizePath(str), schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path) != 0 {
+			t.Fatalf(`path %q: expected the empty path of the master key, got %v`, str, path)
+		}
+	}
+	if _, err := hdsk.Path(h, "m / 42 / 0", schema); err == nil {
+		t.Fatalf(`expected Path to leave unnormalized paths unchanged`)
+	}
+}
+
+// TestStrToIndex is a test for precomputing string indices.
+func TestStrToIndex(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: str / purpose: any")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path(h, "m/wallet/signing", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, label := range []string{"wallet", "signing"} {
+		index, err := hdsk.StrToIndex(h, label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != path[i] {
+			t.Fatalf(`label %q: expected %d, got %d`, label, path[i], index)
+		}
+	}
+}
+
+// TestPathAnnotated is a test for parsing derivation paths with labels and raw indices.
+func TestPathAnnotated(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: str / purpose: any / context: hex / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := "m/wallet/007/0a/01"
+	path, err := hdsk.Path(h, str, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotated, err := hdsk.PathAnnotated(h, str, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []hdsk.AnnotatedIndex{
+		{Label: "application", Raw: "wallet", Index: path[0]},
+		{Label: "purpose", Raw: "007", Index: 7},
+		{Label: "context", Raw: "0a", Index: 10},
+		{Label: "index", Raw: "01", Index: 1},
+	}
+	if !slices.Equal(annotated, expected) {
+		t.Fatalf(`expected %v, got %v`, expected, annotated)
+	}
+	master, err := hdsk.PathAnnotated(h, "m", schema)
+	if err != nil || len(master) != 0 {
+		t.Fatalf(`expected empty path, got %v, %v`, master, err)
+	}
+	for _, str := range []string{"x/1", "m/wallet/0/0a/1/2", "m/wallet//0a", "m/wallet/0/zz"} {
+		_, expected := hdsk.Path(h, str, schema)
+		_, err := hdsk.PathAnnotated(h, str, schema)
+		if err == nil || err.Error() != expected.Error() {
+			t.Fatalf(`path %q: expected %v, got %v`, str, expected, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Package primitives provides the low-level building blocks of hdsk key derivation, for verifying
+// fingerprints and reproducing salts independently of the hdsk package.
+package primitives
+
+import (
+	"hash"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// CalcSalt creates a 16 byte salt from a given hash, message, and optional context info, as used
+// in key derivation.
+func CalcSalt(h func() hash.Hash, msg, info []byte) ([]byte, error) {
+	return utils.CalcSalt(h, msg, info)
+}
+
+// Fingerprint calculates a 16 byte fingerprint from a given hash, parent key, and child key, as
+// held by derived keys.
+func Fingerprint(h func() hash.Hash, parent, child []byte) ([]byte, error) {
+	return utils.Fingerprint(h, parent, child)
+}
@@ -0,0 +1,55 @@
+package primitives_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+	"github.com/jacobhaap/go-hdsk/primitives"
+)
+
+// TestCalcSalt is a test for creating salts.
+func TestCalcSalt(t *testing.T) {
+	h := sha256.New
+	msg := []byte("message")
+	salt, err := primitives.CalcSalt(h, msg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(h, make([]byte, 16)) // Salt without info is keyed by 16 zero bytes
+	if _, err := mac.Write(append(msg, "SALT"...)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(salt, mac.Sum(nil)[:16]) {
+		t.Fatalf(`salt without info does not match the HMAC construction`)
+	}
+	salted, err := primitives.CalcSalt(h, msg, []byte{0, 0, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salted) != 16 || bytes.Equal(salt, salted) {
+		t.Fatalf(`expected a distinct 16 byte salt with info`)
+	}
+}
+
+// TestFingerprint is a test for calculating fingerprints.
+func TestFingerprint(t *testing.T) {
+	h := sha256.New
+	master, err := hdsk.Master(h, bytes.Repeat([]byte{0x42, 0x24}, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := hdsk.Child(h, &master, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := primitives.Fingerprint(h, master.Key, child.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fp, child.Fingerprint) {
+		t.Fatalf(`fingerprint does not match the child key fingerprint`)
+	}
+}
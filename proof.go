@@ -0,0 +1,51 @@
+package hdsk
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+)
+
+// NodeWithProof derives a new key at a node in a hierarchy descending from a master key, like
+// Node, from a given hash, master key, and derivation path, also returning a proof of the
+// derivation as the fingerprint of the key at each level of the path. The proof holds no key
+// material, and is stored alongside the key as an audit trail for VerifyProof.
+func NodeWithProof(h func() hash.Hash, master *HDKey, path HDPath) (HDKey, [][]byte, error) {
+	proof := make([][]byte, 0, len(path)) // Allocate slice for the fingerprints of each level
+	key, err := Params{}.walk(master, len(path), children(h, path), func(level *HDKey) {
+		proof = append(proof, bytes.Clone(level.Fingerprint)) // Add the fingerprint of each level
+	})
+	if err != nil {
+		return HDKey{}, nil, err
+	}
+	return key, proof, nil // Return the HD key and the proof
+}
+
+// VerifyProof checks a proof of the derivation of a key from a master key, from a given hash,
+// master key, derivation path, key, and proof returned by NodeWithProof. Verification derives the
+// key at each level of the path, comparing each fingerprint of the proof and the final key in
+// constant time, so it costs the same as deriving the key with Node.
+func VerifyProof(h func() hash.Hash, master *HDKey, path HDPath, leaf *HDKey, proof [][]byte) (bool, error) {
+	if len(proof) != len(path) {
+		return false, fmt.Errorf(`proof must have a fingerprint for each of %d indices, got %d`, len(path), len(proof))
+	}
+	result, i := 1, 0 // Result of every comparison, and the current level
+	key, err := Params{}.walk(master, len(path), children(h, path), func(level *HDKey) {
+		result &= subtle.ConstantTimeCompare(level.Fingerprint, proof[i]) // Compare the fingerprint of each level in constant time
+		i++
+	})
+	if err != nil {
+		return false, fmt.Errorf(`proof verification, %w`, err)
+	}
+	equal := leaf.Equal(&key)        // Compare the final key in constant time
+	return result == 1 && equal, nil // Return the result of every comparison
+}
+
+// children returns a function deriving the child of a parent key for each position of a
+// derivation path, from a given hash and derivation path.
+func children(h func() hash.Hash, path HDPath) func(parent *HDKey, i int) (HDKey, error) {
+	return func(parent *HDKey, i int) (HDKey, error) {
+		return Child(h, parent, path[i]) // Derive a child of the parent for the current index
+	}
+}
@@ -0,0 +1,74 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestProof is a test for proving and verifying the derivation of nodes.
+func TestProof(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	path := hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 0}
+	node, proof, err := hdsk.NodeWithProof(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := hdsk.Node(h, &master, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.Equal(&expected) {
+		t.Fatalf(`expected the same node as Node`)
+	}
+	if len(proof) != len(path) || !bytes.Equal(proof[len(proof)-1], node.Fingerprint) {
+		t.Fatalf(`expected a fingerprint for each level, ending with the node`)
+	}
+	ok, err := hdsk.VerifyProof(h, &master, path, &node, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf(`expected the proof to verify`)
+	}
+	tampered := make([][]byte, len(proof))
+	for i := range proof {
+		tampered[i] = bytes.Clone(proof[i])
+	}
+	tampered[1][0] ^= 1
+	other, err := hdsk.Node(h, &master, hdsk.HDPath{42, 0, 1 | hdsk.Hardened, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		leaf  *hdsk.HDKey
+		proof [][]byte
+	}{{&node, tampered}, {&other, proof}} {
+		ok, err := hdsk.VerifyProof(h, &master, path, c.leaf, c.proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Fatalf(`expected a tampered proof or key not to verify`)
+		}
+	}
+	if _, err := hdsk.VerifyProof(h, &master, path, &node, proof[:3]); err == nil {
+		t.Fatalf(`expected error for a proof of the wrong length`)
+	}
+	root, empty, err := hdsk.NodeWithProof(h, &master, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = master.Clone()
+	root.Zero()
+	if len(empty) != 0 || !master.Equal(&expected) {
+		t.Fatalf(`expected an empty proof and a copy of the master key for an empty path`)
+	}
+	if _, _, err := hdsk.NodeWithProof(h, &master, make(hdsk.HDPath, hdsk.MaxDepth+1)); !errors.Is(err, hdsk.ErrMaxDepth) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrMaxDepth, err)
+	}
+}
@@ -0,0 +1,217 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// SchemaBuilder builds a new derivation path schema from segments added in order, without
+// formatting the schema as a string.
+type SchemaBuilder struct {
+	pairs [][2]string // Labels and types of the added segments.
+}
+
+// Add adds a segment to the schema from a given label and type, returning the builder for
+// chaining. A label ending in "?" marks the segment as optional, as in Schema.
+func (b *SchemaBuilder) Add(label, typ string) *SchemaBuilder {
+	b.pairs = append(b.pairs, [2]string{label, typ})
+	return b
+}
+
+// Build builds the derivation path schema from the added segments, with the same validation of
+// labels, types, and the number of segments as Schema.
+func (b *SchemaBuilder) Build() (HDSchema, error) {
+	if len(b.pairs)+1 > 256 {
+		return nil, fmt.Errorf(`schema cannot exceed 256 segments, got %d, %w`, len(b.pairs)+1, ErrSchemaTooManySegments)
+	}
+	return buildSchema(b.pairs)
+}
+
+// String returns the derivation path schema as a string, including any bounds, patterns, and
+// optional markers of its segments. The string parses back to an equal schema with Schema.
+func (s HDSchema) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, segment := range s {
+		label, typ := segment[0], segment[1]
+		if t, opt := strings.CutSuffix(typ, "?"); opt {
+			label, typ = label+"?", t // Move the optional marker to the label
+		}
+		b.WriteString(" / " + label + ": " + typ) // Add each segment as label: type
+	}
+	return b.String()
+}
+
+// Len returns the number of segments of the derivation path schema, excluding the "m" root, which
+// is the number of indices of a full derivation path. A wildcard segment counts as one segment.
+func (s HDSchema) Len() int {
+	return len(s)
+}
+
+// Labels returns the labels of the segments of the derivation path schema, in order.
+func (s HDSchema) Labels() []string {
+	labels := make([]string, 0, len(s)) // Allocate slice for the labels
+	for _, segment := range s {
+		labels = append(labels, segment[0]) // Add the label of each segment
+	}
+	return labels
+}
+
+// Each calls a given function for each segment of the derivation path schema in order, with the
+// position, label, and type of the segment, stopping at and returning the first error. The type
+// includes the "*" marker of wildcard segments and the "?" marker of optional segments.
+func (s HDSchema) Each(fn func(pos int, label, typ string) error) error {
+	for i, segment := range s {
+		if err := fn(i, segment[0], segment[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexOf returns the position of the segment of the derivation path schema with a given label,
+// and whether the schema has a segment with the label.
+func (s HDSchema) IndexOf(label string) (int, bool) {
+	for i, segment := range s {
+		if segment[0] == label {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Leaves returns the derivation paths of the leaves under a prefix of the derivation path schema,
+// from a given hash, prefix, start index, and end index, for each final index from start up to but
+// not including end. The prefix must have an index for every segment but the last, parsed with
+// Path, and the last segment must be of a numeric or any type, with the range within its bounds.
+// The hash is needed because validating the prefix with Path hashes its string indices, and
+// patterns and types are checked as part of that parsing. Any hash validates the same prefixes,
+// and the leaves returned do not depend on it.
+func (s HDSchema) Leaves(h func() hash.Hash, prefix string, start, end uint32) ([]string, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf(`schema has no segment for leaves, %w`, ErrMissingIndex)
+	}
+	segments := strings.Split(prefix, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf(`prefix must begin with %q, got %q, %w`, "m", segments[0], ErrBadRoot)
+	}
+	switch indices := len(segments) - 1; {
+	case indices > len(s)-1:
+		return nil, fmt.Errorf(`prefix must have %d indices, got %d, %w`, len(s)-1, indices, ErrTooManyIndices)
+	case indices < len(s)-1:
+		return nil, fmt.Errorf(`prefix must have %d indices, got %d, %w`, len(s)-1, indices, ErrMissingIndex)
+	}
+	if _, err := Path(h, prefix, s[:len(s)-1]); err != nil {
+		return nil, fmt.Errorf(`invalid prefix %q, %w`, prefix, err)
+	}
+	if err := s.leafRange(h, start, end); err != nil {
+		return nil, err
+	}
+	leaves := make([]string, 0, end-start) // Allocate slice for the leaf paths
+	for index := start; index < end; index++ {
+		leaves = append(leaves, prefix+"/"+strconv.FormatUint(uint64(index), 10)) // Add the path of each leaf
+	}
+	return leaves, nil // Return the leaf paths
+}
+
+// leafRange checks a range of final indices of leaves from a given hash, start index, and end
+// index against the type of the final segment of the derivation path schema.
+func (s HDSchema) leafRange(h func() hash.Hash, start, end uint32) error {
+	label, typ := s.segment(len(s) - 1) // Get the label and type of the final segment
+	if base := utils.BaseType(typ); base != "num" && base != "any" {
+		return fmt.Errorf(`final segment %q of type %q cannot take numeric indices`, label, typ)
+	}
+	if start > end {
+		return fmt.Errorf(`range start %d exceeds end %d`, start, end)
+	}
+	if start == end {
+		return nil // An empty range has no indices to check
+	}
+	for _, index := range []uint32{start, end - 1} {
+		if _, err := utils.GetIndex(h, strconv.FormatUint(uint64(index), 10), typ); err != nil {
+			return fmt.Errorf(`range of final segment %q, %w`, label, err) // Bounded ranges only need their first and last indices checked
+		}
+	}
+	return nil
+}
+
+// ID calculates a stable identifier for the derivation path schema from a given hash. The
+// identifier is a hash of the schema as a string, so equivalent schemas share an identifier.
+func (s HDSchema) ID(h func() hash.Hash) []byte {
+	hasher := h()
+	hasher.Write([]byte(s.String())) // Write the schema to the hash, writes to a hash never fail
+	return hasher.Sum(nil)           // Return the hash digest as the identifier
+}
+
+// splitSchema splits a derivation path schema into segments at a given separator, trimming the
+// whitespace around each segment. Separators within parentheses, and characters escaped with a
+// backslash, do not separate segments.
+func splitSchema(str, sep string) []string {
+	var segments []string
+	depth, start := 0, 0 // Depth of parentheses, and start of the current segment
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '\\':
+			i++ // Skip the escaped character
+		case str[i] == '(':
+			depth++
+		case str[i] == ')' && depth > 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(str[i:], sep):
+			segments = append(segments, strings.TrimSpace(str[start:i])) // Add the segment preceding the separator
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(segments, strings.TrimSpace(str[start:])) // Add the final segment
+}
+
+// wildcard checks if the final segment of the derivation path schema is a wildcard segment.
+func (s HDSchema) wildcard() bool {
+	return len(s) > 0 && strings.HasSuffix(s[len(s)-1][1], "*")
+}
+
+// capacity returns the maximum number of indices of a derivation path under the derivation path
+// schema, which is unlimited with a wildcard segment.
+func (s HDSchema) capacity() int {
+	if s.wildcard() {
+		return math.MaxInt
+	}
+	return len(s)
+}
+
+// segment returns the label and type of the segment of the derivation path schema for a given
+// position of a derivation path, without the optional and wildcard markers of the type. Positions
+// beyond the final segment return the wildcard segment.
+func (s HDSchema) segment(i int) (string, string) {
+	segment := s[min(i, len(s)-1)]
+	typ := strings.TrimSuffix(segment[1], "?") // Remove the optional marker from the type
+	typ = strings.TrimSuffix(typ, "*")         // Remove the wildcard marker from the type
+	return segment[0], typ
+}
+
+// required returns the number of indices a derivation path must have under the derivation path
+// schema. Without optional segments, every index may be omitted.
+func (s HDSchema) required() (int, error) {
+	first := -1 // Position of the first optional segment
+	for i, segment := range s {
+		opt := strings.HasSuffix(segment[1], "?")
+		switch {
+		case strings.HasSuffix(segment[1], "*"):
+			continue // Wildcard segments take any number of indices, including none
+		case opt && first < 0:
+			first = i
+		case !opt && first >= 0:
+			return 0, fmt.Errorf(`required segment %q cannot follow optional segment %q in schema, %w`, segment[0], s[first][0], ErrInvalidSegment)
+		}
+	}
+	if first < 0 {
+		return 0, nil
+	}
+	return first, nil // Return the number of segments preceding the optional segments
+}
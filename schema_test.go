@@ -0,0 +1,455 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestSchemaID is a test for derivation path schema identifiers.
+func TestSchemaID(t *testing.T) {
+	h := sha256.New
+	schema1, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema2, err := hdsk.Schema("m / application:any / purpose :  any / context: any / index:num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema1.ID(h), schema2.ID(h)) {
+		t.Fatalf(`equivalent schemas must share an identifier`)
+	}
+	schema3, err := hdsk.Schema("m / application: any / purpose: any / context: any / index: any")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(schema1.ID(h), schema3.ID(h)) {
+		t.Fatalf(`distinct schemas must not share an identifier`)
+	}
+}
+
+// TestSchemaOptional is a test for optional segments in derivation path schemas.
+func TestSchemaOptional(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: any / purpose: num / context?: any / index?: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.String() != "m / application: any / purpose: num / context?: any / index?: num" {
+		t.Fatalf(`unexpected schema string %q`, schema.String())
+	}
+	for _, str := range []string{"m/42/0", "m/42/0/1", "m/42/0/1/0"} {
+		if _, err := hdsk.Path(h, str, schema); err != nil {
+			t.Fatalf(`path %s: %v`, str, err)
+		}
+	}
+	for _, str := range []string{"m", "m/42"} {
+		if _, err := hdsk.Path(h, str, schema); err == nil {
+			t.Fatalf(`expected error for missing required index in %s`, str)
+		}
+	}
+	for _, str := range []string{
+		"m / application: any / purpose?: num / context: any",
+		"m / application?: any / purpose: num",
+		"m / ?: num",
+	} {
+		if _, err := hdsk.Schema(str); err == nil {
+			t.Fatalf(`expected error for schema %q`, str)
+		}
+	}
+	invalid := hdsk.HDSchema{{"application", "any?"}, {"purpose", "num"}} // Build a contradictory schema directly
+	if _, err := hdsk.Path(h, "m/42/0", invalid); err == nil {
+		t.Fatalf(`expected error for required segment following an optional segment`)
+	}
+}
+
+// TestSchemaBounds is a test for bounded numeric segments in derivation path schemas.
+func TestSchemaBounds(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: any / index: num(0..1000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hdsk.Path(h, "m/42/1000", schema); err != nil {
+		t.Fatal(err)
+	}
+	_, err = hdsk.Path(h, "m/42/1001", schema)
+	if err == nil {
+		t.Fatalf(`expected error for index outside of bounds`)
+	}
+	if !strings.Contains(err.Error(), `"index"`) || !strings.Contains(err.Error(), "0..1000") {
+		t.Fatalf(`expected error naming the label and range, got %q`, err)
+	}
+	if _, err := hdsk.Schema("m / index: num(1000..0)"); err == nil {
+		t.Fatalf(`expected error for inverted bounds`)
+	}
+}
+
+// TestSchemaPattern is a test for string segments with a pattern in derivation path schemas.
+func TestSchemaPattern(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: str(/^[a-z]+:[0-9]+$/) / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hdsk.Path(h, "m/vault:1/0", schema); err != nil {
+		t.Fatal(err)
+	}
+	_, err = hdsk.Path(h, "m/Vault/0", schema)
+	if err == nil {
+		t.Fatalf(`expected error for index not matching pattern`)
+	}
+	if !strings.Contains(err.Error(), `"application"`) {
+		t.Fatalf(`expected error naming the label, got %q`, err)
+	}
+	if _, err := hdsk.Schema("m / application: str(/[a-z/) / index: num"); err == nil {
+		t.Fatalf(`expected error for invalid pattern at schema time`)
+	}
+}
+
+// TestSchemaHex is a test for schemas with hexadecimal segments.
+func TestSchemaHex(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: hex / purpose: any!hex / index: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := hdsk.Path(h, "m/0a0b0c0d/ff/0", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0] != 0x0A0B0C0D || path[1] != 0xFF {
+		t.Fatalf(`expected hexadecimal indices, got %v`, path)
+	}
+	_, err = hdsk.Path(h, "m/0a0b0g/ff/0", schema)
+	if err == nil {
+		t.Fatalf(`expected error for invalid hexadecimal index`)
+	}
+	if !strings.Contains(err.Error(), `"application"`) {
+		t.Fatalf(`expected error naming the label, got %q`, err)
+	}
+	if _, err := hdsk.Path(h, "m/2a'/ff/0", schema); err == nil {
+		t.Fatalf(`expected error for hardened hexadecimal index`)
+	}
+}
+
+// TestSchemaString is a test for the string form of derivation path schemas.
+func TestSchemaString(t *testing.T) {
+	for _, str := range []string{
+		hdsk.DefaultSchema,
+		"m",
+		"m / application: str(/^[a-z]+$/) / purpose: num(0..1000) / context?: any / index?: num",
+	} {
+		schema, err := hdsk.Schema(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if schema.String() != str {
+			t.Fatalf(`expected %q, got %q`, str, schema.String())
+		}
+		parsed, err := hdsk.Schema(schema.String()) // Parse the string form of the schema
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(schema, parsed) {
+			t.Fatalf(`expected %v to round trip, got %v`, schema, parsed)
+		}
+	}
+}
+
+// TestSchemaLabels is a test for reading the length and labels of schemas.
+func TestSchemaLabels(t *testing.T) {
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Len() != 4 {
+		t.Fatalf(`expected 4 segments, got %d`, schema.Len())
+	}
+	if labels := schema.Labels(); !slices.Equal(labels, []string{"application", "purpose", "context", "index"}) {
+		t.Fatalf(`unexpected labels %q`, labels)
+	}
+	root, err := hdsk.Schema("m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Len() != 0 || len(root.Labels()) != 0 {
+		t.Fatalf(`expected no segments for the root schema`)
+	}
+}
+
+// TestSchemaDuplicateLabel is a test for rejecting schemas with duplicate labels.
+func TestSchemaDuplicateLabel(t *testing.T) {
+	if _, err := hdsk.Schema(hdsk.DefaultSchema); err != nil {
+		t.Fatal(err)
+	}
+	for _, schema := range []string{"m / a: any / a: any", "m / a: any / b: num / a?: str"} {
+		_, err := hdsk.Schema(schema)
+		if !errors.Is(err, hdsk.ErrInvalidSegment) {
+			t.Fatalf(`schema %q: expected %v, got %v`, schema, hdsk.ErrInvalidSegment, err)
+		}
+		if !strings.Contains(err.Error(), `"a"`) {
+			t.Fatalf(`expected error naming the duplicate label, got %q`, err)
+		}
+	}
+}
+
+// TestSchemaMissingSeparator is a test for rejecting schema segments without a separator.
+func TestSchemaMissingSeparator(t *testing.T) {
+	for _, schema := range []string{"m / index", "m / application: any / index num"} {
+		if _, err := hdsk.Schema(schema); !errors.Is(err, hdsk.ErrInvalidSegment) {
+			t.Fatalf(`schema %q: expected %v, got %v`, schema, hdsk.ErrInvalidSegment, err)
+		}
+	}
+}
+
+// TestSchemaLeaves is a test for enumerating the leaves under a prefix.
+func TestSchemaLeaves(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves, err := schema.Leaves(h, "m/42/0/1", 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(leaves, []string{"m/42/0/1/0", "m/42/0/1/1", "m/42/0/1/2"}) {
+		t.Fatalf(`unexpected leaves %q`, leaves)
+	}
+	for _, leaf := range leaves {
+		if _, err := hdsk.ParsePathStrict(h, leaf, schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, prefix := range []string{"m/42/0", "m/42/0/1/0", "n/42/0/1"} {
+		if _, err := schema.Leaves(h, prefix, 0, 3); err == nil {
+			t.Fatalf(`expected error for prefix %q`, prefix)
+		}
+	}
+	if _, err := schema.Leaves(h, "m/42/0/1", 3, 2); err == nil {
+		t.Fatalf(`expected error for start exceeding end`)
+	}
+	named, err := hdsk.Schema("m / application: any / name: str")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := named.Leaves(h, "m/42", 0, 3); err == nil {
+		t.Fatalf(`expected error for a final string segment`)
+	}
+	high, err := schema.Leaves(h, "m/42/0/1", 1<<32-3, 1<<32-1) // Full 32 bit indices in the default mode
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(high, []string{"m/42/0/1/4294967293", "m/42/0/1/4294967294"}) {
+		t.Fatalf(`unexpected leaves %q`, high)
+	}
+	for _, leaf := range high {
+		if _, err := hdsk.ParsePathStrict(h, leaf, schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bounded, err := hdsk.Schema("m / application: num(0..9) / index: num(10..19)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves, err = bounded.Leaves(h, "m/4", 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaves) != 10 || leaves[0] != "m/4/10" || leaves[9] != "m/4/19" {
+		t.Fatalf(`unexpected bounded leaves %q`, leaves)
+	}
+	for _, r := range [][2]uint32{{9, 12}, {15, 21}} {
+		if _, err := bounded.Leaves(h, "m/4", r[0], r[1]); !errors.Is(err, hdsk.ErrIndexOutOfRange) {
+			t.Fatalf(`range %d..%d: expected %v, got %v`, r[0], r[1], hdsk.ErrIndexOutOfRange, err)
+		}
+	}
+	if _, err := bounded.Leaves(h, "m/10", 10, 20); !errors.Is(err, hdsk.ErrIndexOutOfRange) {
+		t.Fatalf(`expected %v for a prefix outside of its bounds, got %v`, hdsk.ErrIndexOutOfRange, err)
+	}
+}
+
+// TestSchemaBuilder is a test for building derivation path schemas from segments.
+func TestSchemaBuilder(t *testing.T) {
+	expected, err := hdsk.Schema("m / application: any / purpose: num(0..1000) / index?: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := new(hdsk.SchemaBuilder).Add("application", "any").Add("purpose", "num(0..1000)").Add("index?", "num").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(schema, expected) {
+		t.Fatalf(`expected schema %v, got %v`, expected, schema)
+	}
+	root, err := new(hdsk.SchemaBuilder).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Len() != 0 {
+		t.Fatalf(`expected no segments for an empty builder, got %d`, root.Len())
+	}
+	for _, test := range []struct {
+		b   *hdsk.SchemaBuilder
+		err error
+	}{
+		{new(hdsk.SchemaBuilder).Add("", "num"), hdsk.ErrInvalidSegment},
+		{new(hdsk.SchemaBuilder).Add("index", "int"), hdsk.ErrInvalidType},
+		{new(hdsk.SchemaBuilder).Add("a", "any").Add("a", "num"), hdsk.ErrInvalidSegment},
+		{new(hdsk.SchemaBuilder).Add("a?", "any").Add("b", "num"), hdsk.ErrInvalidSegment},
+	} {
+		if _, err := test.b.Build(); !errors.Is(err, test.err) {
+			t.Fatalf(`expected %v, got %v`, test.err, err)
+		}
+	}
+	b := new(hdsk.SchemaBuilder)
+	for i := range 256 {
+		b.Add("index"+strconv.Itoa(i), "num")
+	}
+	if _, err := b.Build(); !errors.Is(err, hdsk.ErrSchemaTooManySegments) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrSchemaTooManySegments, err)
+	}
+}
+
+// TestSchemaWildcard is a test for wildcard segments taking any number of indices.
+func TestSchemaWildcard(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema("m / application: str / purpose: num / tail: num(0..100)*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.String() != "m / application: str / purpose: num / tail: num(0..100)*" {
+		t.Fatalf(`unexpected schema string %q`, schema.String())
+	}
+	fixed, err := hdsk.Path(h, "m/app/0", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tail := range [][]uint32{nil, {7}, {1, 2, 3, 100, 0}} {
+		str := "m/app/0"
+		for _, index := range tail {
+			str += "/" + strconv.FormatUint(uint64(index), 10)
+		}
+		path, err := hdsk.Path(h, str, schema)
+		if err != nil {
+			t.Fatalf(`path %s: %v`, str, err)
+		}
+		if !slices.Equal(path, slices.Concat(fixed, tail)) {
+			t.Fatalf(`path %s: expected tail %v, got %v`, str, tail, path)
+		}
+	}
+	if _, err := hdsk.Path(h, "m/app/0/1/101", schema); !errors.Is(err, hdsk.ErrIndexOutOfRange) {
+		t.Fatalf(`expected the wildcard type for every tail index, got %v`, err)
+	}
+	if _, err := hdsk.Path(h, "m/app/0/1/x", schema); err == nil || !strings.Contains(err.Error(), `"tail"`) {
+		t.Fatalf(`expected error naming the wildcard label, got %v`, err)
+	}
+	for _, str := range []string{
+		"m / tail: num* / index: num",
+		"m / index: num / tail?: num*",
+		"m / index: num / tail: *",
+	} {
+		if _, err := hdsk.Schema(str); err == nil {
+			t.Fatalf(`expected error for schema %q`, str)
+		}
+	}
+	if _, err := hdsk.Schema("m / index?: num / tail: num*"); err != nil {
+		t.Fatalf(`expected wildcard segment after optional segment, got %v`, err)
+	}
+	node := hdsk.HDKey{Depth: 9}
+	if node.IsLeaf(schema) || node.DepthBeyondSchema(schema) {
+		t.Fatalf(`expected no leaves or keys beyond a schema with a wildcard segment`)
+	}
+}
+
+// TestSchemaEach is a test for iterating over the segments of derivation path schemas.
+func TestSchemaEach(t *testing.T) {
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels, types []string
+	err = schema.Each(func(pos int, label, typ string) error {
+		if pos != len(labels) {
+			t.Fatalf(`expected position %d, got %d`, len(labels), pos)
+		}
+		labels, types = append(labels, label), append(types, typ)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(labels, schema.Labels()) || !slices.Equal(types, []string{"any", "any", "any", "num"}) {
+		t.Fatalf(`unexpected labels %v and types %v`, labels, types)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = schema.Each(func(pos int, label, typ string) error {
+		calls++
+		if label == "purpose" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Fatalf(`expected to stop at the second segment, got %d calls, %v`, calls, err)
+	}
+}
+
+// TestSchemaSep is a test for parsing schemas with other separators and whitespace.
+func TestSchemaSep(t *testing.T) {
+	expected, err := hdsk.Schema("m / application: str(/^[a-z]+$/) / purpose: any / index?: num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		str, sep string
+	}{
+		{"m,application: str(/^[a-z]+$/), purpose: any ,index?: num", ","},
+		{"m > application: str(/^[a-z]+$/) > purpose: any > index?: num", ">"},
+		{"m/application: str(/^[a-z]+$/)/purpose: any/index?: num", "/"},
+		{"m / application: str(/^[a-z]+$/) / purpose: any / index?: num", " / "},
+	} {
+		schema, err := hdsk.SchemaSep(c.str, c.sep)
+		if err != nil {
+			t.Fatalf(`schema %q: %v`, c.str, err)
+		}
+		if !slices.Equal(schema, expected) {
+			t.Fatalf(`schema %q: expected %v, got %v`, c.str, expected, schema)
+		}
+	}
+	for _, str := range []string{
+		"m/application: str(/^[a-z]+$/)/purpose: any/index?: num",
+		"m /application: str(/^[a-z]+$/)  /  purpose: any/ index?: num",
+		" m / application: str(/^[a-z]+$/) / purpose: any / index?: num ",
+	} {
+		schema, err := hdsk.Schema(str)
+		if err != nil {
+			t.Fatalf(`schema %q: %v`, str, err)
+		}
+		if !slices.Equal(schema, expected) {
+			t.Fatalf(`schema %q: expected %v, got %v`, str, expected, schema)
+		}
+	}
+	pattern, err := hdsk.Schema("m / path: str(/^a / b$/)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pattern.String() != "m / path: str(/^a / b$/)" {
+		t.Fatalf(`unexpected schema string %q`, pattern.String())
+	}
+	if _, err := hdsk.SchemaSep(hdsk.DefaultSchema, ""); err == nil {
+		t.Fatalf(`expected error for an empty separator`)
+	}
+	if _, err := hdsk.Schema("n/index: num"); !errors.Is(err, hdsk.ErrBadRoot) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrBadRoot, err)
+	}
+}
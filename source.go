@@ -0,0 +1,31 @@
+package hdsk
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// SecretSource is a source of a secret held outside of ordinary memory, such as a locked buffer
+// of a memory protection library. Bytes returns the secret without copying it, and Destroy wipes
+// and releases the secret.
+type SecretSource interface {
+	Bytes() []byte // Secret held by the source.
+	Destroy()      // Wipe and release the secret.
+}
+
+// MasterFromSource derives a new master key from a given hash and secret source, producing the
+// same master key as Master with the bytes of the source. The secret is read in place without
+// being copied, and the source is destroyed once the master key is derived, including on error.
+// The derived master key is held in ordinary memory.
+func MasterFromSource(h func() hash.Hash, src SecretSource) (HDKey, error) {
+	if src == nil {
+		return HDKey{}, errors.New(`master key requires a secret source`)
+	}
+	defer src.Destroy()                // Destroy the source after use
+	key, err := Master(h, src.Bytes()) // Derive the master key from the secret in place
+	if err != nil {
+		return HDKey{}, fmt.Errorf(`secret source, %w`, err)
+	}
+	return key, nil // Return the master HD key
+}
@@ -0,0 +1,55 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// source is a secret source that records whether it was destroyed.
+type source struct {
+	secret    []byte
+	destroyed bool
+}
+
+// Bytes returns the secret of the source.
+func (s *source) Bytes() []byte { return s.secret }
+
+// Destroy wipes the secret of the source.
+func (s *source) Destroy() {
+	clear(s.secret)
+	s.destroyed = true
+}
+
+// TestMasterFromSource is a test for deriving master keys from secret sources.
+func TestMasterFromSource(t *testing.T) {
+	h := sha256.New
+	secret := []byte("a secret held in a locked buffer")
+	expected, err := hdsk.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &source{secret: append([]byte(nil), secret...)}
+	master, err := hdsk.MasterFromSource(h, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !master.Equal(&expected) {
+		t.Fatalf(`expected the master key of the secret`)
+	}
+	if !src.destroyed {
+		t.Fatalf(`expected the source to be destroyed`)
+	}
+	weak := &source{secret: make([]byte, 32)}
+	if _, err := hdsk.MasterFromSource(h, weak); !errors.Is(err, hdsk.ErrKnownWeakSecret) {
+		t.Fatalf(`expected %v, got %v`, hdsk.ErrKnownWeakSecret, err)
+	}
+	if !weak.destroyed {
+		t.Fatalf(`expected the source to be destroyed on error`)
+	}
+	if _, err := hdsk.MasterFromSource(h, nil); err == nil {
+		t.Fatalf(`expected error for a nil source`)
+	}
+}
@@ -0,0 +1,66 @@
+package hdsk
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+
+	"github.com/jacobhaap/go-hdsk/internal/utils"
+)
+
+// stream is a keystream expanded on demand, one block per counter value.
+type stream struct {
+	mac     hash.Hash // HMAC keyed by the stream key
+	info    []byte    // STREAM + nonce as info for each block
+	counter uint64    // Counter of the next block
+	block   []byte    // Unread bytes of the current block
+}
+
+// Stream creates a deterministic keystream bound to a key, from a given hash and nonce. The key
+// and chain code are extracted into a stream key, and each block of the keystream is an HMAC of
+// STREAM, the nonce, and an 8 byte big-endian block counter, so distinct nonces produce
+// independent keystreams and blocks are only derived as they are read.
+func (k *HDKey) Stream(h func() hash.Hash, nonce []byte) (io.Reader, error) {
+	salt, err := utils.CalcSalt(h, k.Key, nil) // Derive salt from the key
+	if err != nil {
+		return nil, fmt.Errorf(`stream salt, %w`, err)
+	}
+	defer clear(salt) // Wipe the salt
+	secret := slices.Concat(k.Key, k.Code)
+	prk, err := hkdf.Extract(h, secret, salt) // Extract the stream key from the key and chain code
+	clear(secret)                             // Wipe the concatenated key and chain code
+	if err != nil {
+		return nil, fmt.Errorf(`stream hkdf, %w`, err)
+	}
+	s := &stream{
+		mac:  hmac.New(h, prk),
+		info: slices.Concat([]byte("STREAM"), nonce),
+	}
+	clear(prk)    // Wipe the stream key, held by the keyed HMAC
+	return s, nil // Return the keystream
+}
+
+// Read reads the next len(p) bytes of the keystream into p.
+func (s *stream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.block) == 0 {
+			var counter [8]byte
+			binary.BigEndian.PutUint64(counter[:], s.counter) // Encode the block counter
+			s.mac.Reset()
+			if _, err := s.mac.Write(slices.Concat(s.info, counter[:])); err != nil {
+				return n, fmt.Errorf(`stream block %d, %w`, s.counter, err)
+			}
+			s.block = s.mac.Sum(nil) // Derive the next block
+			s.counter++
+		}
+		c := copy(p[n:], s.block) // Copy unread bytes of the block
+		s.block = s.block[c:]
+		n += c
+	}
+	return n, nil
+}
@@ -0,0 +1,37 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+// TestStream is a test for reading keystreams.
+func TestStream(t *testing.T) {
+	h := sha256.New
+	master := testMaster(t, h)
+	read := func(nonce []byte, sizes ...int) []byte {
+		s, err := master.Stream(h, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []byte
+		for _, size := range sizes {
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(s, buf); err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, buf...)
+		}
+		return out
+	}
+	whole := read([]byte("nonce"), 200)
+	parts := read([]byte("nonce"), 1, 31, 32, 7, 129)
+	if !bytes.Equal(whole, parts) {
+		t.Fatalf(`expected the same keystream for one read and several small reads`)
+	}
+	if bytes.Equal(whole, read([]byte("other"), 200)) {
+		t.Fatalf(`expected distinct keystreams for distinct nonces`)
+	}
+}
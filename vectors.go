@@ -0,0 +1,31 @@
+package hdsk
+
+import (
+	"fmt"
+	"hash"
+)
+
+// Vector is a test vector, holding a derivation path and the key derived at its node.
+type Vector struct {
+	Path string // Derivation path.
+	Key  []byte // Key derived at the node of the derivation path.
+}
+
+// Vectors generates test vectors from a given hash, secret, schema, and derivation paths, deriving
+// the key at the node of each derivation path from a master key of the secret. Test vectors are
+// commonly derived from weak secrets, such as 32 zero bytes, so known weak secrets are allowed.
+func Vectors(h func() hash.Hash, secret []byte, schema HDSchema, paths []string) ([]Vector, error) {
+	master, err := Params{AllowWeak: true}.Master(h, secret) // Derive the master key from the secret
+	if err != nil {
+		return nil, fmt.Errorf(`vectors, %w`, err)
+	}
+	vectors := make([]Vector, 0, len(paths)) // Allocate slice for the test vectors
+	for _, str := range paths {
+		node, err := DerivePath(h, &master, str, schema) // Derive the node for the derivation path
+		if err != nil {
+			return nil, fmt.Errorf(`vector %q, %w`, str, err)
+		}
+		vectors = append(vectors, Vector{Path: str, Key: node.Key})
+	}
+	return vectors, nil // Return the test vectors
+}
@@ -0,0 +1,37 @@
+package hdsk_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// TestVectors is a test for generating the test vectors.
+func TestVectors(t *testing.T) {
+	h := sha256.New
+	schema, err := hdsk.Schema(hdsk.DefaultSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(vectors))
+	for _, v := range vectors {
+		paths = append(paths, v.path)
+	}
+	generated, err := hdsk.Vectors(h, make([]byte, 32), schema, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(generated) != len(vectors) {
+		t.Fatalf(`expected %d vectors, got %d`, len(vectors), len(generated))
+	}
+	for i, v := range generated {
+		if v.Path != vectors[i].path || hex.EncodeToString(v.Key) != vectors[i].key {
+			t.Fatalf(`mismatch for %s: expected %s, got %x`, vectors[i].path, vectors[i].key, v.Key)
+		}
+	}
+	if _, err := hdsk.Vectors(h, make([]byte, 32), schema, []string{"m/42/0/1/0/0"}); err == nil {
+		t.Fatalf(`expected error for invalid derivation path`)
+	}
+}
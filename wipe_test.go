@@ -0,0 +1,76 @@
+package hdsk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"testing"
+
+	"github.com/jacobhaap/go-hdsk"
+)
+
+// errTarget is returned by a failing hash when the target is written.
+var errTarget = errors.New(`target written`)
+
+// failingHash is a hash that fails when written the target, recording the written slice.
+type failingHash struct {
+	hash.Hash
+	target  []byte
+	written *[]byte
+}
+
+// Write writes p to the hash, failing after writing the target.
+func (f failingHash) Write(p []byte) (int, error) {
+	n, err := f.Hash.Write(p)
+	if err == nil && bytes.Equal(p, f.target) {
+		*f.written = p // Record the slice holding the target
+		return n, errTarget
+	}
+	return n, err
+}
+
+// failing returns a hash constructor failing when written the target.
+func failing(target []byte, written *[]byte) func() hash.Hash {
+	return func() hash.Hash {
+		return failingHash{Hash: sha256.New(), target: target, written: written}
+	}
+}
+
+// zeroed checks if every byte of a slice is zero.
+func zeroed(b []byte) bool {
+	return len(b) > 0 && bytes.Count(b, []byte{0}) == len(b)
+}
+
+// TestWipeOnError is a test for wiping intermediate key material when derivation fails.
+func TestWipeOnError(t *testing.T) {
+	h := sha256.New
+	secret := bytes.Repeat([]byte{0x42, 0x24}, 16)
+	master, err := hdsk.Master(h, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []byte
+	_, err = hdsk.Master(failing(bytes.Clone(master.Key), &written), secret) // Fail when fingerprinting the master key
+	if !errors.Is(err, errTarget) {
+		t.Fatalf(`expected %v, got %v`, errTarget, err)
+	}
+	if !zeroed(written) {
+		t.Fatalf(`expected master key to be wiped after error`)
+	}
+	p := hdsk.Params{Hardening: true} // Derive a hardened child for the flagged index
+	for _, index := range []uint32{0, hdsk.Hardened} {
+		child, err := p.Child(h, &master, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written = nil
+		_, err = p.Child(failing(bytes.Clone(child.Key), &written), &master, index) // Fail when fingerprinting the child key
+		if !errors.Is(err, errTarget) {
+			t.Fatalf(`index %d: expected %v, got %v`, index, errTarget, err)
+		}
+		if !zeroed(written) {
+			t.Fatalf(`index %d: expected child key to be wiped after error`, index)
+		}
+	}
+}